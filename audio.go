@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"ebiten-demo/game"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// EbitenSoundLoader returns a game.LoadSound that plays the sounds game decodes with ebiten audio players on a context.
+// The context must run at game.SampleRate.
+func EbitenSoundLoader(context *audio.Context) func(path string, loop bool) (game.Sound, error) {
+	return func(path string, loop bool) (game.Sound, error) {
+		pcm, err := game.DecodePCM(path)
+		if err != nil {
+			return nil, err
+		}
+		var src io.Reader = pcm
+		if loop {
+			src = &game.Loop{PCM: pcm}
+		}
+		player, err := context.NewPlayer(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return player, nil
+	}
+}
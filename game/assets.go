@@ -0,0 +1,212 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LoadSprites reads a sprites json file and loads the images it lists from the same directory
+func LoadSprites(path string) (map[string]Sprite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonSprites []SpriteJSON
+	if err := json.Unmarshal(data, &jsonSprites); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+
+	sprites := map[string]Sprite{}
+	for _, v := range jsonSprites {
+		img, err := LoadImage(filepath.Join(filepath.Dir(path), v.Image))
+		if err != nil {
+			return nil, err
+		}
+
+		// camelCase the filenames without the extension to make sprite keys
+		k := CamelCase(v.Image[:len(v.Image)-4])
+
+		sprites[k] = Sprite{
+			Key:         k,
+			FrameDur:    v.FrameDur,
+			FrameLen:    v.FrameLen,
+			FrameHeight: v.FrameHeight,
+			FrameWidth:  v.FrameWidth,
+			Handles:     v.Handles,
+			Image:       img,
+		}
+	}
+	return sprites, nil
+}
+
+// LoadDialogueGraphs reads a dialogue json file. The first node of each dialogue is its root.
+func LoadDialogueGraphs(path string) (map[string]*DialogueGraph, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonDialogues map[string][]DialogueJSON
+	if err := json.Unmarshal(data, &jsonDialogues); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+
+	dialogueGraphs := map[string]*DialogueGraph{}
+	for k, v := range jsonDialogues {
+		graph := DialogueGraph{
+			Nodes: map[string]*DialogueNode{},
+			Edges: map[string][]DialogueEdge{},
+		}
+		for i := 0; i < len(v); i++ {
+			node, err := v[i].Node()
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s[%d].%w", path, k, i, err)
+			}
+			graph.Nodes[v[i].ID] = node
+			for j, c := range v[i].Connections {
+				condition, err := ParseCondition(c.Condition)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s[%d].connections[%d].condition: %w", path, k, i, j, err)
+				}
+				graph.Edges[v[i].ID] = append(graph.Edges[v[i].ID], DialogueEdge{Next: c.Next, Condition: condition})
+			}
+			if i == 0 {
+				graph.RootKey = v[i].ID
+				graph.NodeKey = v[i].ID
+			}
+		}
+
+		// Connections and options can only lead to nodes of the same dialogue, and options only along the connections
+		// of their node
+		for i := 0; i < len(v); i++ {
+			connections := map[string]bool{}
+			for j, c := range v[i].Connections {
+				if _, ok := graph.Nodes[c.Next]; !ok {
+					return nil, fmt.Errorf("%s: %s[%d].connections[%d]: unknown node %q", path, k, i, j, c.Next)
+				}
+				connections[c.Next] = true
+			}
+			for j, o := range v[i].Options {
+				if _, ok := graph.Nodes[o.Next]; !ok {
+					return nil, fmt.Errorf("%s: %s[%d].options[%d]: unknown node %q", path, k, i, j, o.Next)
+				}
+				if !connections[o.Next] {
+					return nil, fmt.Errorf("%s: %s[%d].options[%d]: leads to %q, which isn't in the connections of the node", path, k, i, j, o.Next)
+				}
+			}
+		}
+
+		dialogueGraphs[k] = &graph
+	}
+	return dialogueGraphs, nil
+}
+
+// Node converts the json representation of a dialogue node into a node
+func (d *DialogueJSON) Node() (*DialogueNode, error) {
+	node := DialogueNode{Phrase: d.Phrase, End: d.End}
+	var err error
+	if node.Condition, err = ParseCondition(d.Condition); err != nil {
+		return nil, fmt.Errorf("condition: %w", err)
+	}
+	if node.Set, err = ParseValues(d.Set); err != nil {
+		return nil, fmt.Errorf("set.%w", err)
+	}
+	for i, o := range d.Options {
+		option := DialogueOption{Text: o.Text, Next: o.Next}
+		if option.Condition, err = ParseCondition(o.Condition); err != nil {
+			return nil, fmt.Errorf("options[%d].condition: %w", i, err)
+		}
+		node.Options = append(node.Options, option)
+	}
+	return &node, nil
+}
+
+// NewGame creates a game with the player standing facing south, ready to enter a level
+func NewGame(sprites map[string]Sprite, dialogueGraphs map[string]*DialogueGraph, input Input) *Game {
+	weapons := []Weapon{
+		{
+			Sprite: sprites["swordEast"],
+			Damage: 1,
+		},
+	}
+
+	// Every dialogue checks and sets the same variables
+	variables := Variables{}
+	for _, graph := range dialogueGraphs {
+		graph.Vars = variables
+	}
+
+	return &Game{
+		Variables: variables,
+		Player: Player{
+			LastDir:   South,
+			Animation: false,
+			FrameNum:  0,
+			Sprite:    sprites["linkStandSouth"],
+			Health:    100,
+			MaxHealth: 100,
+			Items:     map[string]int{},
+			Weapon:    &weapons[0],
+			Motion:    Motion{Speed: 1},
+		},
+		Camera: Camera{
+			ScrollDuration: 48,
+		},
+		CollisionRules: DefaultCollisionRules(),
+		DamageRules:    DefaultDamageRules(),
+		Scenes:         []Scene{&PlayScene{}},
+		Settings:       DefaultSettings(),
+		Weapons:        weapons,
+		Sprites:        sprites,
+		DialogueGraphs: dialogueGraphs,
+		Locales:        map[string]*Locale{DefaultLocale: DefaultLocaleOf(dialogueGraphs)},
+		Input:          input,
+	}
+}
+
+// LoadGame loads the sprites, dialogue and its locales, loot tables and sounds from the sprites, dialogue, loot and sounds directories under dir,
+// and creates a game that has entered the level at levelPath
+func LoadGame(dir string, levelPath string, input Input) (*Game, error) {
+	sprites, err := LoadSprites(filepath.Join(dir, "sprites", "sprites.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	dialogueGraphs, err := LoadDialogueGraphs(filepath.Join(dir, "dialogue", "dialogue.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	locales, err := LoadLocales(filepath.Join(dir, "dialogue"), dialogueGraphs)
+	if err != nil {
+		return nil, err
+	}
+
+	lootTables, err := LoadLootTables(filepath.Join(dir, "loot", "loot.json"), sprites)
+	if err != nil {
+		return nil, err
+	}
+
+	sounds, err := LoadSounds(filepath.Join(dir, "sounds", "sounds.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	level, err := LoadLevel(levelPath, sprites, dialogueGraphs)
+	if err != nil {
+		return nil, err
+	}
+	if clip, ok := sounds[level.Music]; level.Music != "" && (!ok || clip.Channel != MusicChannel) {
+		return nil, fmt.Errorf("%s: music: unknown music %q", levelPath, level.Music)
+	}
+
+	g := NewGame(sprites, dialogueGraphs, input)
+	g.Locales = locales
+	g.LootTables = lootTables
+	g.Sounds = sounds
+	g.EnterLevel(level)
+	return g, nil
+}
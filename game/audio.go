@@ -0,0 +1,143 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	SwordSound    = "swordSwing"      // Played when the player swings their sword
+	FireballSound = "fireball"        // Played when an enemy shoots a fireball
+	HitSound      = "playerHit"       // Played when the player is damaged
+	DialogueSound = "dialogueAdvance" // Played when the player advances a dialogue
+	VolumeStep    = 20                // How much the volume of a channel changes by in the settings menu, in percent
+)
+
+// Channel is a group of sounds that share a volume setting
+type Channel int
+
+const (
+	MusicChannel Channel = iota
+	EffectsChannel
+	VoiceChannel
+	ChannelCount // The number of channels, not a channel itself
+)
+
+// ChannelNames are the names of channels used in the sound manifest
+var ChannelNames = map[string]Channel{
+	"music": MusicChannel,
+	"sfx":   EffectsChannel,
+	"voice": VoiceChannel,
+}
+
+// channelLabels are the names of channels shown in the settings menu
+var channelLabels = [ChannelCount]string{"Music", "Effects", "Voice"}
+
+// Sound is a decoded sound that can be played. The main package plays sounds with ebiten's audio package.
+type Sound interface {
+	Play()
+	Pause()
+	Rewind() error
+	IsPlaying() bool
+	SetVolume(volume float64) // Sets the volume from 0 to 1
+}
+
+// LoadSound loads the sound file at path, looping it forever if loop is true.
+// It decodes the file into a sound played without a sound device by default, and the main package replaces it to play sounds.
+var LoadSound = DecodeSoundFile
+
+// SoundClip is a sound loaded from the sound manifest
+type SoundClip struct {
+	Sound   Sound
+	Channel Channel // The channel that sets the volume of the sound
+	Volume  float64 // The volume of the sound from 0 to 1, before the volume of its channel
+}
+
+// SoundJSON represents the json of a sound in the sound manifest
+type SoundJSON struct {
+	File    string   `json:"file"`    // The OGG or WAV file of the sound, relative to the manifest
+	Channel string   `json:"channel"` // The channel of the sound. Sounds on the music channel loop.
+	Volume  *float64 `json:"volume"`  // The volume of the sound from 0 to 1, or 1 if missing
+}
+
+// LoadSounds reads a sound manifest and loads each sound in it. Sounds are keyed by their camelCased file names, like sprites.
+func LoadSounds(path string) (map[string]SoundClip, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var jsonSounds []SoundJSON
+	if err := json.Unmarshal(data, &jsonSounds); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+
+	sounds := map[string]SoundClip{}
+	for i, v := range jsonSounds {
+		ext := strings.ToLower(filepath.Ext(v.File))
+		if ext != ".ogg" && ext != ".wav" {
+			return nil, fmt.Errorf("%s: [%d].file: must be an OGG or WAV file, got %q", path, i, v.File)
+		}
+		channel, ok := ChannelNames[v.Channel]
+		if !ok {
+			return nil, fmt.Errorf("%s: [%d].channel: unknown channel %q", path, i, v.Channel)
+		}
+		volume := 1.0
+		if v.Volume != nil {
+			volume = *v.Volume
+		}
+		if volume < 0 || volume > 1 {
+			return nil, fmt.Errorf("%s: [%d].volume: must be from 0 to 1, got %v", path, i, volume)
+		}
+
+		sound, err := LoadSound(filepath.Join(filepath.Dir(path), v.File), channel == MusicChannel)
+		if err != nil {
+			return nil, err
+		}
+		sounds[CamelCase(v.File[:len(v.File)-len(ext)])] = SoundClip{Sound: sound, Channel: channel, Volume: volume}
+	}
+	return sounds, nil
+}
+
+// PlaySound plays a sound effect from the beginning. Unknown sounds are ignored, so games without sounds are silent.
+func (g *Game) PlaySound(key string) {
+	clip, ok := g.Sounds[key]
+	if !ok {
+		return
+	}
+	clip.Sound.SetVolume(g.Volume(clip))
+	if err := clip.Sound.Rewind(); err != nil {
+		log.Println(err)
+		return
+	}
+	clip.Sound.Play()
+}
+
+// Volume returns the volume a sound plays at, which is its own volume scaled by the volume setting of its channel
+func (g *Game) Volume(clip SoundClip) float64 {
+	return clip.Volume * float64(g.Settings.Volumes[clip.Channel]) / 100
+}
+
+// UpdateAudio starts the music of the current level when it changes, and applies the volume settings to playing sounds
+func UpdateAudio(g *Game) {
+	music := ""
+	if g.Level != nil {
+		music = g.Level.Music
+	}
+	if music != g.Music {
+		if clip, ok := g.Sounds[g.Music]; ok {
+			clip.Sound.Pause()
+		}
+		g.Music = music
+		g.PlaySound(music)
+	}
+
+	for _, clip := range g.Sounds {
+		if clip.Sound.IsPlaying() {
+			clip.Sound.SetVolume(g.Volume(clip))
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// silent returns the sound a game loaded without a sound device for a key
+func silent(t *testing.T, g *Game, key string) *PCMSound {
+	t.Helper()
+	clip, ok := g.Sounds[key]
+	if !ok {
+		t.Fatalf("expected a sound %q, got %v", key, g.Sounds)
+	}
+	return clip.Sound.(*PCMSound)
+}
+
+func TestLoadSounds(t *testing.T) {
+	sounds, err := LoadSounds("../sounds/sounds.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{SwordSound, FireballSound, HitSound, DialogueSound} {
+		if clip, ok := sounds[key]; !ok || clip.Sound.(*PCMSound).Loop {
+			t.Errorf("expected a one-shot sound %q, got %+v", key, clip)
+		}
+	}
+	if clip := sounds["field"]; clip.Channel != MusicChannel || !clip.Sound.(*PCMSound).Loop {
+		t.Errorf("expected looping field music, got %+v", clip)
+	}
+
+	dir := t.TempDir()
+	tests := map[string]string{
+		"unknown channel": `[{"file": "a.wav", "channel": "ambience"}]`,
+		"loud volume":     `[{"file": "a.wav", "channel": "sfx", "volume": 1.5}]`,
+		"mp3 file":        `[{"file": "a.mp3", "channel": "sfx"}]`,
+		"not a wav":       `[{"file": "b.wav", "channel": "sfx"}]`,
+	}
+	writeWAV(t, filepath.Join(dir, "a.wav"), 1, SampleRate, 16, []int{0})
+	if err := os.WriteFile(filepath.Join(dir, "b.wav"), []byte("OggS"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for name, manifest := range tests {
+		path := filepath.Join(dir, "sounds.json")
+		if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSounds(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestMusicAndEffectVolumes(t *testing.T) {
+	g := newTestGame(t, "testdata/wizard.json", Hold(1), Hold(1, Attack), Hold(1))
+	g.Level.Music = "field"
+	g.Settings.Volumes[MusicChannel] = 50
+	g.Settings.Volumes[EffectsChannel] = 40
+
+	step(t, g, 1)
+	music := silent(t, g, "field")
+	if !music.Playing || music.Volume != 0.3 {
+		t.Errorf("expected the level music to play at 60%% of half volume, got %+v", music)
+	}
+
+	step(t, g, 2)
+	sword := silent(t, g, SwordSound)
+	if sword.Plays != 1 || sword.Volume != 0.4 {
+		t.Errorf("expected the sword swing to play once at the effects volume, got %+v", sword)
+	}
+
+	g.Settings.Volumes[MusicChannel] = 0
+	g.Level.Music = ""
+	UpdateAudio(g)
+	if music.Playing {
+		t.Error("expected the music to stop when the level has none")
+	}
+}
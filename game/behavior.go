@@ -0,0 +1,125 @@
+package game
+
+var AttackCommands = []string{"attack_west", "attack_east", "attack_north", "attack_south"}
+
+type Behavior struct {
+	Command string    // The name of the command for the enemy to execute
+	Key     Direction // Any key associated with the command, like a direction
+	Pause   int       // The wait time between new commands
+	Paused  int       // How long the behavior has been paused since the last action
+}
+
+// MoveEnemy moves an enemy by dx and dy with Move and returns true if it moved at all. Walking into the player counts as touching it.
+func MoveEnemy(g *Game, e *Enemy, dx, dy float64) bool {
+	x, y := e.X, e.Y
+	for _, c := range Move(g, e, dx, dy) {
+		if _, ok := c.(*Player); ok {
+			g.EnemyCollision = e
+		}
+	}
+	return e.X != x || e.Y != y
+}
+
+// ShootProjectile fires a projectile from an enemy
+func ShootProjectile(g *Game, p Projectile) {
+	g.Projectiles = append(g.Projectiles, p)
+	g.PlaySound(FireballSound)
+}
+
+func AdvanceBehavior(g *Game, e *Enemy) {
+	if Contains(AttackCommands, e.Behavior.Command) && e.Behavior.Paused < e.Behavior.Pause {
+		e.Behavior.Paused++
+		return
+	} else {
+		e.Behavior.Paused = 0
+	}
+	playerRect := g.Player.Hitbox(0, 0)
+	enemyRect := e.Hitbox(0, 0)
+	playerX := playerRect.Min.X + playerRect.Dx()/2
+	enemyX := enemyRect.Min.X + enemyRect.Dx()/2
+	if enemyX == playerX {
+		if enemyRect.Max.Y < playerRect.Max.Y {
+			e.Behavior.Command = "attack_south"
+			e.Sprite = g.Sprites["skeletonWizardAttackSouth"]
+			ShootProjectile(g, Projectile{
+				X:        float64(enemyX),
+				Y:        float64(enemyRect.Max.Y),
+				Sprite:   g.Sprites["fireballSouth"],
+				FrameNum: 0,
+				Motion:   Motion{Speed: 3},
+				Dir:      South,
+				IsEnemy:  true,
+			})
+		} else if enemyRect.Max.Y > playerRect.Max.Y {
+			e.Behavior.Command = "attack_north"
+			e.Sprite = g.Sprites["skeletonWizardAttackNorth"]
+			ShootProjectile(g, Projectile{
+				X:        float64(enemyX),
+				Y:        float64(enemyRect.Min.Y),
+				Sprite:   g.Sprites["fireballNorth"],
+				FrameNum: 0,
+				Motion:   Motion{Speed: 3},
+				Dir:      North,
+				IsEnemy:  true,
+			})
+		}
+	} else if enemyRect.Max.Y == playerRect.Max.Y {
+		if enemyRect.Max.X < playerRect.Max.X {
+			e.Behavior.Command = "attack_east"
+			e.Sprite = g.Sprites["skeletonWizardAttackEast"]
+			ShootProjectile(g, Projectile{
+				X:        float64(enemyRect.Max.X),
+				Y:        float64(enemyRect.Max.Y),
+				Sprite:   g.Sprites["fireballEast"],
+				FrameNum: 0,
+				Motion:   Motion{Speed: 3},
+				Dir:      East,
+				IsEnemy:  true,
+			})
+		} else if enemyRect.Max.X > playerRect.Max.X {
+			e.Behavior.Command = "attack_west"
+			e.Sprite = g.Sprites["skeletonWizardAttackWest"]
+			ShootProjectile(g, Projectile{
+				X:        float64(enemyRect.Min.X),
+				Y:        float64(enemyRect.Max.Y),
+				Sprite:   g.Sprites["fireballWest"],
+				FrameNum: 0,
+				Motion:   Motion{Speed: 3},
+				Dir:      West,
+				IsEnemy:  true,
+			})
+		}
+	} else {
+		xDiff := AbsDiff(enemyX, playerX)
+		yDiff := AbsDiff(enemyRect.Max.Y, playerRect.Max.Y)
+		if xDiff < yDiff {
+			if enemyX < playerX {
+				e.Steer(1, 0)
+				if MoveEnemy(g, e, e.VX, e.VY) {
+					e.Behavior.Command = "walk_east"
+					e.Sprite = g.Sprites["skeletonWizardWalkEast"]
+				}
+			} else {
+				e.Steer(-1, 0)
+				if MoveEnemy(g, e, e.VX, e.VY) {
+					e.Behavior.Command = "walk_west"
+					e.Sprite = g.Sprites["skeletonWizardWalkWest"]
+				}
+			}
+		} else {
+			if enemyRect.Max.Y < playerRect.Max.Y {
+				e.Steer(0, 1)
+				if MoveEnemy(g, e, e.VX, e.VY) {
+					e.Behavior.Command = "walk_south"
+					e.Sprite = g.Sprites["skeletonWizardWalkSouth"]
+				}
+			} else {
+				e.Steer(0, -1)
+				if MoveEnemy(g, e, e.VX, e.VY) {
+					e.Behavior.Command = "walk_north"
+					e.Sprite = g.Sprites["skeletonWizardWalkNorth"]
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package game
+
+import (
+	"fmt"
+	"image"
+)
+
+const (
+	ScreenWidth  = 320 // The width of the logical screen in pixels
+	ScreenHeight = 240 // The height of the logical screen in pixels
+)
+
+// CameraMode is how the camera moves through the world
+type CameraMode int
+
+const (
+	CameraFollow CameraMode = iota // The camera stays centered on the player
+	CameraRoom                     // The world is split into screen sized rooms and the camera scrolls from room to room
+)
+
+// Camera represents the part of the world that is drawn to the screen
+type Camera struct {
+	X              int             // The world X offset of the left edge of the screen
+	Y              int             // The world Y offset of the top edge of the screen
+	Bounds         image.Rectangle // The part of the world the camera is allowed to show
+	Mode           CameraMode      // How the camera moves through the world
+	Room           image.Point     // The room the camera is showing or scrolling to in room mode
+	ScrollDuration int             // How many frames a scroll to an adjacent room takes
+	Scroll         int             // How many frames are left in the current scroll, or 0
+	ScrollFrom     image.Point     // The camera offset the current scroll started at
+}
+
+// ParseCameraMode converts the name of a camera mode in a level file into a CameraMode
+func ParseCameraMode(s string) (CameraMode, error) {
+	switch s {
+	case "", "follow":
+		return CameraFollow, nil
+	case "room":
+		return CameraRoom, nil
+	default:
+		return CameraFollow, fmt.Errorf("unknown camera mode %q", s)
+	}
+}
+
+// Follow centers the camera on a world position, clamped so nothing outside of the bounds is shown.
+// If the bounds are smaller than the screen, the bounds are centered on the screen instead.
+func (c *Camera) Follow(x, y int) {
+	c.X = ClampView(x-ScreenWidth/2, c.Bounds.Min.X, c.Bounds.Max.X, ScreenWidth)
+	c.Y = ClampView(y-ScreenHeight/2, c.Bounds.Min.Y, c.Bounds.Max.Y, ScreenHeight)
+}
+
+// RoomAt returns the room containing a world position. Rooms are counted from the top left of the bounds.
+func (c *Camera) RoomAt(x, y int) image.Point {
+	return image.Pt(FloorDiv(x-c.Bounds.Min.X, ScreenWidth), FloorDiv(y-c.Bounds.Min.Y, ScreenHeight))
+}
+
+// RoomRect returns the world rectangle of a room
+func (c *Camera) RoomRect(room image.Point) image.Rectangle {
+	min := c.Bounds.Min.Add(image.Pt(room.X*ScreenWidth, room.Y*ScreenHeight))
+	return image.Rectangle{Min: min, Max: min.Add(image.Pt(ScreenWidth, ScreenHeight))}
+}
+
+// RoomOffset returns the camera offset that shows a room, clamped to the bounds for rooms cut off by the edge of the world
+func (c *Camera) RoomOffset(room image.Point) image.Point {
+	r := c.RoomRect(room)
+	return image.Pt(
+		ClampView(r.Min.X, c.Bounds.Min.X, c.Bounds.Max.X, ScreenWidth),
+		ClampView(r.Min.Y, c.Bounds.Min.Y, c.Bounds.Max.Y, ScreenHeight),
+	)
+}
+
+// ShowRoom moves the camera to a room immediately
+func (c *Camera) ShowRoom(room image.Point) {
+	c.Room = room
+	c.Scroll = 0
+	offset := c.RoomOffset(room)
+	c.X, c.Y = offset.X, offset.Y
+}
+
+// ScrollTo starts a timed scroll from the current view to a room
+func (c *Camera) ScrollTo(room image.Point) {
+	if c.ScrollDuration <= 0 {
+		c.ShowRoom(room)
+		return
+	}
+	c.Room = room
+	c.Scroll = c.ScrollDuration
+	c.ScrollFrom = image.Pt(c.X, c.Y)
+}
+
+// AdvanceScroll moves the camera one frame further along the current scroll
+func (c *Camera) AdvanceScroll() {
+	if c.Scroll <= 0 {
+		return
+	}
+	c.Scroll--
+	to := c.RoomOffset(c.Room)
+	elapsed := c.ScrollDuration - c.Scroll
+	c.X = c.ScrollFrom.X + (to.X-c.ScrollFrom.X)*elapsed/c.ScrollDuration
+	c.Y = c.ScrollFrom.Y + (to.Y-c.ScrollFrom.Y)*elapsed/c.ScrollDuration
+}
+
+// IsScrolling returns true if the camera is in the middle of a scroll to another room
+func (c *Camera) IsScrolling() bool {
+	return c.Scroll > 0
+}
+
+// IsActive returns true if a world position is in the part of the world being simulated.
+// In room mode only the current room is active, and everything else is suspended.
+func (c *Camera) IsActive(x, y int) bool {
+	if c.Mode != CameraRoom {
+		return true
+	}
+	return image.Pt(x, y).In(c.RoomRect(c.Room))
+}
+
+// View returns the world rectangle currently shown on the screen
+func (c *Camera) View() image.Rectangle {
+	return image.Rect(c.X, c.Y, c.X+ScreenWidth, c.Y+ScreenHeight)
+}
+
+// ClampView clamps the offset of a view of the given size so it stays within min and max
+func ClampView(offset, min, max, size int) int {
+	if max-min < size {
+		return min - (size-(max-min))/2
+	}
+	return Min(Max(offset, min), max-size)
+}
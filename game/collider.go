@@ -1,4 +1,4 @@
-package main
+package game
 
 import (
 	"image"
@@ -6,6 +6,7 @@ import (
 
 type Collider interface {
 	Hitbox(x, y int) image.Rectangle
+	CollisionLayer() CollisionLayer // The layer the collider is on, which decides what it blocks
 }
 
 // Hitbox returns a player hitbox rectangle offset by x and y, and simulates perspective
@@ -15,7 +16,7 @@ func (p *Player) Hitbox(x, y int) image.Rectangle {
 	// To simulate perspective, we also limit the hitbox to the bottom half of the sprite by translating the min point down (positive Y) by half the sprite height
 	// This results in a translating up (negative Y by half the sprite height)
 	offset := p.Sprite.FrameHeight / 2
-	return image.Rect(p.X+x-p.Sprite.FrameWidth/2, p.Y+y-offset, p.X+x+p.Sprite.FrameWidth/2, p.Y+y)
+	return image.Rect(Pixel(p.X)+x-p.Sprite.FrameWidth/2, Pixel(p.Y)+y-offset, Pixel(p.X)+x+p.Sprite.FrameWidth/2, Pixel(p.Y)+y)
 }
 
 // Hitbox returns a character hitbox rectangle offset by x and y, and simulates perspective
@@ -25,7 +26,7 @@ func (c *Character) Hitbox(x, y int) image.Rectangle {
 	// To simulate perspective, we also limit the hitbox to the bottom half of the sprite by translating the min point down (positive Y) by half the sprite height
 	// This results in a translating up (negative Y by half the sprite height)
 	offset := c.Sprite.FrameHeight / 2
-	return image.Rect(c.X+x-c.Sprite.FrameWidth/2, c.Y+y-offset, c.X+x+c.Sprite.FrameWidth/2, c.Y+y)
+	return image.Rect(Pixel(c.X)+x-c.Sprite.FrameWidth/2, Pixel(c.Y)+y-offset, Pixel(c.X)+x+c.Sprite.FrameWidth/2, Pixel(c.Y)+y)
 }
 
 // Hitbox returns a character hitbox rectangle offset by x and y, and simulates perspective
@@ -35,7 +36,7 @@ func (e *Enemy) Hitbox(x, y int) image.Rectangle {
 	// To simulate perspective, we also limit the hitbox to the bottom half of the sprite by translating the min point down (positive Y) by half the sprite height
 	// This results in a translating up (negative Y by half the sprite height)
 	offset := e.Sprite.FrameHeight / 2
-	return image.Rect(e.X+x-e.Sprite.FrameWidth/2, e.Y+y-offset, e.X+x+e.Sprite.FrameWidth/2, e.Y+y)
+	return image.Rect(Pixel(e.X)+x-e.Sprite.FrameWidth/2, Pixel(e.Y)+y-offset, Pixel(e.X)+x+e.Sprite.FrameWidth/2, Pixel(e.Y)+y)
 }
 
 // Hitbox returns a doodad hitbox rectangle offset by x and y
@@ -61,5 +62,95 @@ func (p Projectile) Hitbox(x, y int) image.Rectangle {
 	// To simulate perspective, we also limit the hitbox to the bottom half of the sprite by translating the min point down (positive Y) by half the sprite height
 	// This results in a translating up (negative Y by half the sprite height)
 	offset := p.Sprite.FrameHeight
-	return image.Rect(p.X+x-p.Sprite.FrameWidth/2, p.Y+y-offset, p.X+x+p.Sprite.FrameWidth/2, p.Y+y)
+	return image.Rect(Pixel(p.X)+x-p.Sprite.FrameWidth/2, Pixel(p.Y)+y-offset, Pixel(p.X)+x+p.Sprite.FrameWidth/2, Pixel(p.Y)+y)
+}
+
+// Hitbox returns a pickup hitbox rectangle offset by x and y. Pickups are small, so the whole sprite counts.
+func (p *Pickup) Hitbox(x, y int) image.Rectangle {
+	return image.Rect(Pixel(p.X)+x-p.Sprite.FrameWidth/2, Pixel(p.Y)+y-p.Sprite.FrameHeight, Pixel(p.X)+x+p.Sprite.FrameWidth/2, Pixel(p.Y)+y)
+}
+
+// Hitbox returns the area the weapon hits in the current frame of its wielder's swing, offset by x and y, or an empty rectangle if it isn't swinging.
+// The weapon sprite points east and is held by its handle, so the frame is placed with the handle in the wielder's hand and turned to face the swing.
+func (w *Weapon) Hitbox(x, y int) image.Rectangle {
+	if !w.IsAttacking || w.Wielder == nil {
+		return image.Rectangle{}
+	}
+	return w.Bounds().Add(image.Pt(x, y))
+}
+
+// Bounds returns the world rect the weapon covers, which is the east-facing sprite turned around its handle in the
+// wielder's hand to face the direction of the swing
+func (w *Weapon) Bounds() image.Rectangle {
+	handle := w.RenderHandle()
+	r := image.Rect(-handle.X, -handle.Y, w.Sprite.FrameWidth-handle.X, w.Sprite.FrameHeight-handle.Y)
+	return w.Turn().ApplyRect(r).Add(w.Hand())
+}
+
+// Hand returns the world position of the wielder's hand in its current frame, where the weapon is held
+func (w *Weapon) Hand() image.Point {
+	return w.Wielder.RenderPosition().Add(w.Wielder.RenderHandle())
+}
+
+func (p *Player) CollisionLayer() CollisionLayer {
+	return PlayerLayer
+}
+
+func (c *Character) CollisionLayer() CollisionLayer {
+	return CharacterLayer
+}
+
+func (e *Enemy) CollisionLayer() CollisionLayer {
+	return EnemyLayer
+}
+
+func (d *Doodad) CollisionLayer() CollisionLayer {
+	return DoodadLayer
+}
+
+func (t *Tile) CollisionLayer() CollisionLayer {
+	return TileLayer
+}
+
+func (p Projectile) CollisionLayer() CollisionLayer {
+	if p.IsEnemy {
+		return EnemyProjectileLayer
+	}
+	return PlayerProjectileLayer
+}
+
+func (p *Player) Position() (float64, float64) {
+	return p.X, p.Y
+}
+
+func (p *Player) Translate(dx, dy float64) {
+	p.X += dx
+	p.Y += dy
+}
+
+func (c *Character) Position() (float64, float64) {
+	return c.X, c.Y
+}
+
+func (c *Character) Translate(dx, dy float64) {
+	c.X += dx
+	c.Y += dy
+}
+
+func (e *Enemy) Position() (float64, float64) {
+	return e.X, e.Y
+}
+
+func (e *Enemy) Translate(dx, dy float64) {
+	e.X += dx
+	e.Y += dy
+}
+
+func (p *Projectile) Position() (float64, float64) {
+	return p.X, p.Y
+}
+
+func (p *Projectile) Translate(dx, dy float64) {
+	p.X += dx
+	p.Y += dy
 }
@@ -0,0 +1,75 @@
+package game
+
+import (
+	"math"
+)
+
+const (
+	FlashInterval   = 4                  // How many ticks something that can't be damaged is shown or hidden for while it flashes
+	PlayerHitDamage = HealthPerHeart / 2 // How much health a hit from an enemy or a projectile takes from the player, half a heart
+)
+
+// Hurt is how long ago something was hit. It can't be damaged again for a while, and is knocked back without control of its movement.
+type Hurt struct {
+	Invulnerable int // How many more ticks it can't be damaged for
+	Knockback    int // How many more ticks it is knocked back for
+}
+
+// DamageRule is how bodies on a layer react to being hit
+type DamageRule struct {
+	Invulnerability int     // How many ticks the body can't be damaged again for after a hit
+	Knockback       int     // How many ticks the body is knocked back for after a hit, without control of its movement
+	KnockbackSpeed  float64 // How many pixels per tick the body is knocked back
+}
+
+// DefaultDamageRules returns how bodies on each layer react to being hit
+func DefaultDamageRules() map[CollisionLayer]DamageRule {
+	return map[CollisionLayer]DamageRule{
+		PlayerLayer: {Invulnerability: 60, Knockback: 8, KnockbackSpeed: 3},
+		EnemyLayer:  {Invulnerability: 20, Knockback: 4, KnockbackSpeed: 2},
+	}
+}
+
+// HitPlayer damages the player and knocks them away from the position of what hit them, unless they can't be damaged yet.
+// It returns true if the player was damaged.
+func HitPlayer(g *Game, damage int, fromX, fromY float64) bool {
+	if g.Player.Invulnerable > 0 {
+		return false
+	}
+	rule := g.DamageRules[PlayerLayer]
+	g.Player.Health -= damage
+	g.Player.Invulnerable = rule.Invulnerability
+	g.Player.Knockback = rule.Knockback
+	g.Player.Knock(g.Player.X-fromX, g.Player.Y-fromY, rule.KnockbackSpeed)
+	return true
+}
+
+// HitEnemy damages an enemy and knocks it away from the position of what hit it, unless it can't be damaged yet.
+// It returns true if the enemy was damaged.
+func HitEnemy(g *Game, e *Enemy, damage int, fromX, fromY float64) bool {
+	if e.Invulnerable > 0 {
+		return false
+	}
+	rule := g.DamageRules[EnemyLayer]
+	e.Health -= damage
+	e.Invulnerable = rule.Invulnerability
+	e.Knockback = rule.Knockback
+	e.Knock(e.X-fromX, e.Y-fromY, rule.KnockbackSpeed)
+	return true
+}
+
+// Knock sets the velocity to a speed in the direction of dx and dy, for knockback.
+// Something knocked from exactly where it stands is knocked down the screen.
+func (m *Motion) Knock(dx, dy, speed float64) {
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		dx, dy, length = 0, 1, 1
+	}
+	m.VX = dx / length * speed
+	m.VY = dy / length * speed
+}
+
+// IsFlashing returns true if something that can't be damaged for a number of ticks is hidden in its flashing
+func IsFlashing(invulnerable int) bool {
+	return invulnerable > 0 && invulnerable/FlashInterval%2 == 1
+}
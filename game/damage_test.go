@@ -0,0 +1,86 @@
+package game
+
+import (
+	"testing"
+)
+
+// newDamageGame creates a game with the player touching an enemy on their left and a doodad 8 pixels to their right
+func newDamageGame() *Game {
+	box := Sprite{FrameWidth: 16, FrameHeight: 16, FrameLen: 1}
+	g := &Game{
+		Player:         Player{X: 116, Y: 100, Sprite: box, Health: 100},
+		Enemies:        []Enemy{{X: 100, Y: 100, Sprite: box, Health: 3}},
+		Doodads:        []Doodad{{X: 140, Y: 100, Sprite: box}},
+		CollisionRules: DefaultCollisionRules(),
+		DamageRules:    DefaultDamageRules(),
+		Input:          &ScriptedInput{},
+	}
+	g.IndexColliders()
+	return g
+}
+
+func TestTouchingEnemyOnlyHurtsOnce(t *testing.T) {
+	g := newDamageGame()
+	rule := g.DamageRules[PlayerLayer]
+	for i := 0; i < rule.Invulnerability; i++ {
+		g.EnemyCollision = &g.Enemies[0]
+		UpdateDamage(g)
+		UpdatePlayer(g)
+	}
+	if g.Player.Health != 100-PlayerHitDamage {
+		t.Errorf("expected touching the enemy to only hurt once while invulnerable, got %d health", g.Player.Health)
+	}
+
+	g.EnemyCollision = &g.Enemies[0]
+	UpdateDamage(g)
+	if g.Player.Health != 100-2*PlayerHitDamage {
+		t.Errorf("expected the enemy to hurt again once invulnerability wore off, got %d health", g.Player.Health)
+	}
+}
+
+func TestKnockbackStopsAtColliders(t *testing.T) {
+	g := newDamageGame()
+	g.EnemyCollision = &g.Enemies[0]
+	UpdateDamage(g)
+	if g.Player.VX <= 0 || g.Player.VY != 0 {
+		t.Fatalf("expected the player to be knocked right, away from the enemy, got velocity (%v,%v)", g.Player.VX, g.Player.VY)
+	}
+	for g.Player.Knockback > 0 {
+		UpdatePlayer(g)
+	}
+	if g.Player.X != 124 {
+		t.Errorf("expected the knockback to stop against the doodad at x 124, got %v", g.Player.X)
+	}
+	if g.Player.VX != 0 || g.Player.VY != 0 {
+		t.Errorf("expected the player to stop once the knockback is over, got velocity (%v,%v)", g.Player.VX, g.Player.VY)
+	}
+}
+
+func TestSwordKnocksEnemyBack(t *testing.T) {
+	g := newDamageGame()
+	e := &g.Enemies[0]
+	if !HitEnemy(g, e, 1, g.Player.X, g.Player.Y) || e.Health != 2 {
+		t.Fatalf("expected the enemy to be hurt, got %d health", e.Health)
+	}
+	if HitEnemy(g, e, 1, g.Player.X, g.Player.Y) || e.Health != 2 {
+		t.Errorf("expected the enemy to be invulnerable right after a hit, got %d health", e.Health)
+	}
+
+	x := e.X
+	for UpdateEnemyHurt(g, e) {
+	}
+	rule := g.DamageRules[EnemyLayer]
+	if want := x - float64(rule.Knockback)*rule.KnockbackSpeed; e.X != want {
+		t.Errorf("expected the enemy to be knocked left to x %v, got %v", want, e.X)
+	}
+
+	var flashes int
+	for ; e.Invulnerable > 0; e.Invulnerable-- {
+		if !e.RenderVisible() {
+			flashes++
+		}
+	}
+	if flashes == 0 {
+		t.Error("expected the enemy to flash while invulnerable")
+	}
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"errors"
+	"image"
+)
+
+const (
+	PlayerDeathSpin     = 8  // How many ticks the player faces each way while spinning in the death sequence
+	PlayerDeathDuration = 64 // How many ticks the death sequence lasts before the game over menu is shown
+	ContinueOption      = "Continue"
+	QuitOption          = "Quit"
+)
+
+// ErrQuit is returned by Game.Update when the player chooses to quit the game
+var ErrQuit = errors.New("quit")
+
+// deathSpin is the order the player turns in while spinning in the death sequence, and the sprite for each way
+var deathSpin = []struct {
+	Dir    Direction
+	Sprite string
+}{
+	{South, "linkStandSouth"},
+	{West, "linkStandWest"},
+	{North, "linkStandNorth"},
+	{East, "linkStandEast"},
+}
+
+// GameOverScene is the menu shown after the player dies, where they pick whether to continue
+type GameOverScene struct {
+	Menu *Menu
+}
+
+// NewGameOverScene creates the game over menu
+func NewGameOverScene() *GameOverScene {
+	return &GameOverScene{Menu: &Menu{Title: "Game Over", Options: []string{ContinueOption, QuitOption}}}
+}
+
+// Update lets the player pick an option of the game over menu. It returns ErrQuit if they quit.
+func (s *GameOverScene) Update(g *Game) error {
+	switch s.Menu.Update(g.Input) {
+	case ContinueOption:
+		g.PopScene()
+		Respawn(g)
+	case QuitOption:
+		return ErrQuit
+	}
+	return nil
+}
+
+// Overlay returns false, as the game over menu replaces the world
+func (s *GameOverScene) Overlay() bool {
+	return false
+}
+
+// KillPlayer starts the death sequence of the player. The rest of the world is frozen until the player continues.
+func KillPlayer(g *Game) {
+	g.Player.Health = 0
+	g.Player.Dying = true
+	g.Player.DeathTime = 0
+	g.Player.Animation = false
+	g.Player.Hurt = Hurt{}
+	g.Player.VX, g.Player.VY = 0, 0
+	if g.Player.Weapon != nil {
+		g.Player.Weapon.IsAttacking = false
+	}
+	g.Player.FrameNum = 0
+	g.Player.FrameDur = 0
+	g.Player.Sprite = g.Sprites["linkStandSouth"]
+}
+
+// UpdatePlayerDeath spins the dying player around, then shows the game over menu
+func UpdatePlayerDeath(g *Game) {
+	g.Player.DeathTime++
+	if g.Player.DeathTime >= PlayerDeathDuration {
+		g.PushScene(NewGameOverScene())
+		return
+	}
+	spin := deathSpin[g.Player.DeathTime/PlayerDeathSpin%len(deathSpin)]
+	g.Player.LastDir = spin.Dir
+	g.Player.Sprite = g.Sprites[spin.Sprite]
+}
+
+// Respawn brings the player back to life at the last checkpoint they reached.
+// Projectiles are despawned and enemies forget what they were doing, so the player isn't hit again straight away.
+func Respawn(g *Game) {
+	g.Player.Dying = false
+	g.Player.DeathTime = 0
+	g.Player.Hurt = Hurt{}
+	g.Player.Health = g.Player.MaxHealth
+	if g.Level.RespawnHealth > 0 {
+		g.Player.Health = Min(g.Level.RespawnHealth, g.Player.MaxHealth)
+	}
+	g.Player.X = float64(g.Checkpoint.X)
+	g.Player.Y = float64(g.Checkpoint.Y)
+	g.Player.VX, g.Player.VY = 0, 0
+	g.Player.LastDir = South
+	g.Player.Sprite = g.Sprites["linkStandSouth"]
+
+	g.Projectiles = nil
+	g.InteractionTarget = nil
+	g.EnemyCollision = nil
+	g.ProjectileCollision = nil
+	for i := range g.Enemies {
+		g.Enemies[i].Behavior.Command = ""
+		g.Enemies[i].Behavior.Paused = 0
+		g.Enemies[i].Knockback = 0
+		g.Enemies[i].VX, g.Enemies[i].VY = 0, 0
+	}
+
+	// The checkpoint can be in another room, which is entered like walking into it
+	if g.Camera.Mode == CameraRoom {
+		if room := g.Camera.RoomAt(Pixel(g.Player.X), Pixel(g.Player.Y)-g.Player.Sprite.FrameHeight/2); room != g.Camera.Room {
+			LeaveRoom(g, g.Camera.Room)
+			EnterRoom(g, room)
+		}
+	}
+	g.IndexColliders()
+	ResetCamera(g)
+}
+
+// UpdateCheckpoints moves the respawn point to the checkpoint the player is standing in
+func UpdateCheckpoints(g *Game) {
+	feet := image.Pt(Pixel(g.Player.X), Pixel(g.Player.Y))
+	for _, r := range g.Level.Checkpoints {
+		if feet.In(r) {
+			g.Checkpoint = CheckpointSpawn(r)
+		}
+	}
+}
+
+// CheckpointSpawn returns where the player respawns for a checkpoint area, which is the bottom middle of it
+func CheckpointSpawn(r image.Rectangle) image.Point {
+	return image.Pt(r.Min.X+r.Dx()/2, r.Max.Y-1)
+}
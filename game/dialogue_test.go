@@ -0,0 +1,108 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConditions(t *testing.T) {
+	vars := Variables{"answeredNo": true, "defeated": 2, "answer": "no", "empty": ""}
+	tests := map[string]bool{
+		"":                   true,
+		"answeredNo":         true,
+		"!answeredNo":        false,
+		"missing":            false,
+		"!missing":           true,
+		"empty":              false,
+		"defeated >= 2":      true,
+		"defeated > 2":       false,
+		"missing < 1":        true,
+		"answer == no":       true,
+		`answer == "no"`:     true,
+		"answer != yes":      true,
+		"answeredNo == true": true,
+		"missing == false":   true,
+		"missing != 0":       false,
+		"answer > 1":         false,
+	}
+	for s, want := range tests {
+		c, err := ParseCondition(s)
+		if err != nil {
+			t.Errorf("ParseCondition(%q): %v", s, err)
+			continue
+		}
+		if got := c.Holds(vars); got != want {
+			t.Errorf("%q = %v, want %v", s, got, want)
+		}
+	}
+
+	for _, s := range []string{"== 1", "a b", "!", "defeated > many"} {
+		if _, err := ParseCondition(s); err == nil {
+			t.Errorf("ParseCondition(%q): expected an error", s)
+		}
+	}
+}
+
+// talk runs an interaction with the elder, picking options in order, and returns the nodes it went through
+func talk(elder *Character, picks ...int) []string {
+	graph := elder.DialogueGraphs[elder.DialogueKey]
+	elder.StartDialogue()
+	nodes := []string{graph.NodeKey}
+	for {
+		if options := elder.Options(); len(options) > 0 {
+			elder.SelectOption(picks[0] - elder.SelectedOption())
+			picks = picks[1:]
+		}
+		exhausted := elder.IsExhausted()
+		elder.AdvancePhrase()
+		if exhausted {
+			return nodes
+		}
+		nodes = append(nodes, graph.NodeKey)
+	}
+}
+
+func TestDialogueDependsOnVariables(t *testing.T) {
+	graphs, err := LoadDialogueGraphs("../dialogue/dialogue.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame(nil, graphs, &ScriptedInput{})
+	elder := &Character{DialogueGraphs: graphs, DialogueKey: "elder"}
+
+	talk(elder, 1)
+	if g.Variables["answeredNo"] != true {
+		t.Fatalf("expected answering no to set answeredNo, got %v", g.Variables)
+	}
+	g.Variables.Add("skeletonWizardDefeated", 1)
+	nodes := talk(elder, 0)
+	want := []string{"elder_wizard_defeated", "elder_wizard_cheer"}
+	if len(nodes) != len(want) || nodes[0] != want[0] || nodes[1] != want[1] {
+		t.Errorf("expected the elder to thank the player and ask again as they answered no, got %v", nodes)
+	}
+	if nodes := talk(elder, 0); len(nodes) != 1 || nodes[0] != "elder_fun" {
+		t.Errorf("expected the elder to be glad after the player answered yes, got %v", nodes)
+	}
+}
+
+func TestLoadDialogueErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"unknown connection":     `{"a": [{"id": "a0", "phrase": "hi", "connections": ["a1"]}]}`,
+		"unknown option":         `{"a": [{"id": "a0", "phrase": "hi", "options": [["ok", "a1"]]}]}`,
+		"bad condition":          `{"a": [{"id": "a0", "phrase": "hi", "condition": "x >"}]}`,
+		"bad set":                `{"a": [{"id": "a0", "phrase": "hi", "set": {"x": 1.5}}]}`,
+		"short option":           `{"a": [{"id": "a0", "phrase": "hi", "options": [["ok"]]}]}`,
+		"option off connections": `{"a": [{"id": "a0", "phrase": "hi", "connections": ["a0"], "options": [["ok", "a1"]]}, {"id": "a1", "phrase": "bye", "end": true}]}`,
+	}
+	for name, dialogue := range tests {
+		path := filepath.Join(dir, "dialogue.json")
+		if err := os.WriteFile(path, []byte(dialogue), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadDialogueGraphs(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// BuiltinFonts are the TTF fonts built into the game, by the name a font manifest uses for them
+var BuiltinFonts = map[string][]byte{
+	"goregular": goregular.TTF,
+}
+
+// FontJSON represents the json of a font face in the font manifest. A face is either a TTF or OTF font drawn at a size,
+// which can be one of the BuiltinFonts instead of a file, or a bitmap pixel font cut out of a PNG image.
+type FontJSON struct {
+	File    string  `json:"file"`    // The TTF, OTF or PNG file of the font, relative to the manifest
+	Builtin string  `json:"builtin"` // The name of one of the BuiltinFonts to use instead of a file
+	Size    float64 `json:"size"`    // The size of a TTF or OTF font in points
+	Glyphs  string  `json:"glyphs"`  // The characters of a bitmap font, in the order of their cells from left to right and top to bottom
+	Width   int     `json:"width"`   // The width of a cell of a bitmap font in pixels
+	Height  int     `json:"height"`  // The height of a cell of a bitmap font in pixels, which is also the line height
+	Ascent  int     `json:"ascent"`  // How far the baseline of a bitmap font is below the top of a cell, or the height if missing
+}
+
+// GlyphFace is a font face that can tell which characters it has glyphs for
+type GlyphFace interface {
+	font.Face
+	HasGlyph(r rune) bool
+}
+
+// LoadFonts reads a font manifest and loads each face in it. Characters are drawn with the first face that has a glyph
+// for them, so the faces after the first fill in its gaps.
+func LoadFonts(path string) (font.Face, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var jsonFonts []FontJSON
+	if err := json.Unmarshal(data, &jsonFonts); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+	if len(jsonFonts) == 0 {
+		return nil, fmt.Errorf("%s: has no fonts", path)
+	}
+
+	var faces FallbackFace
+	for i, v := range jsonFonts {
+		file := filepath.Join(filepath.Dir(path), v.File)
+		var face GlyphFace
+		switch ext := strings.ToLower(filepath.Ext(v.File)); {
+		case v.Builtin != "":
+			if v.File != "" {
+				return nil, fmt.Errorf("%s: [%d]: has both a file and a builtin font", path, i)
+			}
+			data, ok := BuiltinFonts[v.Builtin]
+			if !ok {
+				return nil, fmt.Errorf("%s: [%d].builtin: unknown font %q", path, i, v.Builtin)
+			}
+			if v.Size <= 0 {
+				return nil, fmt.Errorf("%s: [%d].size: must be positive, got %v", path, i, v.Size)
+			}
+			face, err = NewTrueTypeFace(data, v.Size)
+		case ext == ".ttf", ext == ".otf":
+			if v.Size <= 0 {
+				return nil, fmt.Errorf("%s: [%d].size: must be positive, got %v", path, i, v.Size)
+			}
+			face, err = LoadTrueTypeFace(file, v.Size)
+		case ext == ".png":
+			if v.Width <= 0 || v.Height <= 0 {
+				return nil, fmt.Errorf("%s: [%d]: width and height must be positive, got %dx%d", path, i, v.Width, v.Height)
+			}
+			if v.Ascent < 0 || v.Ascent > v.Height {
+				return nil, fmt.Errorf("%s: [%d].ascent: must be from 0 to the height, got %d", path, i, v.Ascent)
+			}
+			face, err = LoadBitmapFace(file, v)
+		default:
+			return nil, fmt.Errorf("%s: [%d].file: must be a TTF, OTF or PNG file, got %q", path, i, v.File)
+		}
+		if err != nil {
+			return nil, err
+		}
+		faces = append(faces, face)
+	}
+	return faces, nil
+}
+
+// TrueTypeFace is a face of a TTF or OTF font
+type TrueTypeFace struct {
+	font.Face
+	Font *sfnt.Font
+	buf  sfnt.Buffer
+}
+
+// LoadTrueTypeFace loads a TTF or OTF font file at a size in points
+func LoadTrueTypeFace(path string, size float64) (*TrueTypeFace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	face, err := NewTrueTypeFace(data, size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return face, nil
+}
+
+// NewTrueTypeFace creates a face of TTF or OTF font data at a size in points
+func NewTrueTypeFace(data []byte, size float64) (*TrueTypeFace, error) {
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, err
+	}
+	return &TrueTypeFace{Face: face, Font: f}, nil
+}
+
+// HasGlyph returns true if the font has a glyph for r other than the missing glyph
+func (f *TrueTypeFace) HasGlyph(r rune) bool {
+	i, err := f.Font.GlyphIndex(&f.buf, r)
+	return err == nil && i != 0
+}
+
+// BitmapFace is a face of a bitmap pixel font, where every glyph is the same size
+type BitmapFace struct {
+	*basicfont.Face
+}
+
+// LoadBitmapFace loads a bitmap font from a PNG image of glyph cells. The alpha of the image is the shape of the glyphs.
+func LoadBitmapFace(path string, v FontJSON) (*BitmapFace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	glyphs := []rune(v.Glyphs)
+	columns := img.Bounds().Dx() / v.Width
+	if columns == 0 || len(glyphs) > columns*(img.Bounds().Dy()/v.Height) {
+		return nil, fmt.Errorf("%s: has room for fewer than %d glyphs of %dx%d", path, len(glyphs), v.Width, v.Height)
+	}
+
+	// basicfont expects the glyphs one under the other, in the order of their ranges, which must be sorted
+	order := make([]int, len(glyphs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool { return glyphs[order[i]] < glyphs[order[j]] })
+	mask := image.NewAlpha(image.Rect(0, 0, v.Width, v.Height*len(glyphs)))
+	var ranges []basicfont.Range
+	for i, cell := range order {
+		if i > 0 && glyphs[cell] == glyphs[order[i-1]] {
+			return nil, fmt.Errorf("%s: glyph %q is in the font more than once", path, glyphs[cell])
+		}
+		src := image.Pt(img.Bounds().Min.X+cell%columns*v.Width, img.Bounds().Min.Y+cell/columns*v.Height)
+		draw.Draw(mask, image.Rect(0, i*v.Height, v.Width, (i+1)*v.Height), img, src, draw.Src)
+		ranges = append(ranges, basicfont.Range{Low: glyphs[cell], High: glyphs[cell] + 1, Offset: i})
+	}
+
+	ascent := v.Ascent
+	if ascent == 0 {
+		ascent = v.Height
+	}
+	return &BitmapFace{Face: &basicfont.Face{
+		Advance: v.Width,
+		Width:   v.Width,
+		Height:  v.Height,
+		Ascent:  ascent,
+		Descent: v.Height - ascent,
+		Mask:    mask,
+		Ranges:  ranges,
+	}}, nil
+}
+
+// HasGlyph returns true if the font has a cell for r
+func (f *BitmapFace) HasGlyph(r rune) bool {
+	for _, rr := range f.Ranges {
+		if r >= rr.Low && r < rr.High {
+			return true
+		}
+	}
+	return false
+}
+
+// FallbackFace draws each character with the first of its faces that has a glyph for it, or with the first face if none do.
+// Lines are measured with the metrics of the first face.
+type FallbackFace []GlyphFace
+
+// face returns the face that draws r
+func (f FallbackFace) face(r rune) GlyphFace {
+	for _, face := range f {
+		if face.HasGlyph(r) {
+			return face
+		}
+	}
+	return f[0]
+}
+
+func (f FallbackFace) Close() error {
+	var err error
+	for _, face := range f {
+		if e := face.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (f FallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.face(r).Glyph(dot, r)
+}
+
+func (f FallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.face(r).GlyphBounds(r)
+}
+
+func (f FallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.face(r).GlyphAdvance(r)
+}
+
+// Kern returns the kerning of two characters drawn with the same face, and 0 between faces
+func (f FallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	if face := f.face(r0); face == f.face(r1) {
+		return face.Kern(r0, r1)
+	}
+	return 0
+}
+
+func (f FallbackFace) Metrics() font.Metrics {
+	return f[0].Metrics()
+}
+
+// HasGlyph returns true if any of the faces has a glyph for r
+func (f FallbackFace) HasGlyph(r rune) bool {
+	return f.face(r).HasGlyph(r)
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestFontsFallBack(t *testing.T) {
+	face, err := LoadFonts("../fonts/fonts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	faces := face.(FallbackFace)
+	if len(faces) != 2 {
+		t.Fatalf("expected a TTF face and a bitmap face, got %d faces", len(faces))
+	}
+	if f := faces.face('a'); f != faces[0] {
+		t.Errorf("expected letters to be drawn with the TTF face, got %T", f)
+	}
+	if f := faces.face('★'); f != faces[1] {
+		t.Errorf("expected a star to fall back to the bitmap face, got %T", f)
+	}
+	if f := faces.face('楽'); f != faces[0] || faces.HasGlyph('楽') {
+		t.Errorf("expected a character no face has to be drawn with the first face, got %T", f)
+	}
+	if advance, ok := face.GlyphAdvance('★'); !ok || advance.Round() != 7 {
+		t.Errorf("expected the star to advance by the width of a bitmap cell, got %v", advance)
+	}
+
+	// The glyph of the star is cut out of its cell of the bitmap
+	bounds, _ := font.BoundString(face, "★")
+	if bounds.Max.X.Round()-bounds.Min.X.Round() != 7 || -bounds.Min.Y.Round() != 7 {
+		t.Errorf("expected the star to be 7 wide and 7 above the baseline, got %v", bounds)
+	}
+	_, mask, maskp, _, ok := face.Glyph(fixed.P(0, 0), '★')
+	if !ok {
+		t.Fatal("expected a glyph for the star")
+	}
+	if _, _, _, a := mask.At(maskp.X+3, maskp.Y).RGBA(); a == 0 {
+		t.Error("expected the top of the star to be drawn")
+	}
+	if _, _, _, a := mask.At(maskp.X, maskp.Y).RGBA(); a != 0 {
+		t.Error("expected the corner of the star cell to be empty")
+	}
+}
+
+func TestLoadFontsErrors(t *testing.T) {
+	dir := t.TempDir()
+	symbols, err := os.ReadFile("../fonts/symbols.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "symbols.png"), symbols, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"no fonts":         `[]`,
+		"unknown type":     `[{"file": "font.woff", "size": 12}]`,
+		"missing size":     `[{"builtin": "goregular"}]`,
+		"missing file":     `[{"file": "missing.ttf", "size": 12}]`,
+		"unknown builtin":  `[{"builtin": "comicsans", "size": 12}]`,
+		"file and builtin": `[{"file": "symbols.png", "builtin": "goregular", "size": 12}]`,
+		"missing width":    `[{"file": "symbols.png", "glyphs": "★", "height": 8}]`,
+		"missing cell":     `[{"file": "symbols.png", "glyphs": "★✓✗?", "width": 7, "height": 8}]`,
+		"duplicate glyph":  `[{"file": "symbols.png", "glyphs": "★★", "width": 7, "height": 8}]`,
+		"bad ascent":       `[{"file": "symbols.png", "glyphs": "★", "width": 7, "height": 8, "ascent": 9}]`,
+	}
+	for name, manifest := range tests {
+		path := filepath.Join(dir, "fonts.json")
+		if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFonts(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
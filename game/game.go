@@ -0,0 +1,385 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"log"
+)
+
+// Game is the state of the game world plus custom struct data.
+// It has no dependency on ebiten so it can be simulated without a window, and the main package draws it.
+type Game struct {
+	Player              Player
+	Characters          []Character
+	Enemies             []Enemy
+	Weapons             []Weapon
+	Projectiles         []Projectile
+	Pickups             []Pickup // The items lying on the ground, like loot dropped by enemies
+	Doodads             []Doodad
+	Tiles               []Tile
+	Colliders           *SpatialHash                     // The index of the player and the tiles, doodads, characters and enemies that block movement
+	CollisionRules      map[CollisionLayer]CollisionRule // How bodies on each layer collide when they move
+	DamageRules         map[CollisionLayer]DamageRule    // How bodies on each layer react to being hit
+	Level               *Level                           // The level the world was loaded from
+	Camera              Camera                           // The view of the world drawn to the screen
+	Checkpoint          image.Point                      // Where the player respawns after dying
+	Scenes              []Scene                          // The stack of scenes, where only the top one is updated
+	Settings            Settings                         // The options picked in the settings menu
+	Slots               map[int][]byte                   // Save slots kept in memory rather than on disk while recording or replaying, or nil
+	RenderTargets       []*RenderTarget
+	Sprites             map[string]Sprite
+	DialogueGraphs      map[string]*DialogueGraph
+	Locales             map[string]*Locale   // The text of dialogue and menus in each language, by locale name
+	LootTables          map[string]LootTable // What each type of enemy drops when it dies
+	Variables           Variables            // The variables dialogue checks and sets, shared with every dialogue graph
+	Sounds              map[string]SoundClip // The music and sound effects from the sound manifest
+	Music               string               // The key of the music that is playing, or ""
+	Seed                int64                // The seed of the random rolls, like loot drops
+	Rolls               int                  // How many random rolls have been made, so a loaded game continues the same sequence
+	Input               Input                // The source of the actions the player takes
+	InteractionTarget   InteractionTarget    // The target of another game element that the player is having a dialogue interaction with, or nil.
+	EnemyCollision      *Enemy
+	ProjectileCollision *Projectile
+}
+
+type InteractionTarget interface {
+	Dialogue() string    // The current dialogue to render
+	Options() [][]string // The options for the current dialogue, or empty
+	SelectOption(int)    // Selects a next or previous option
+	SelectedOption() int // Returns the selected option
+	AdvanceRune()        // Advances to the next rune
+	AdvancePhrase()      // Advances to the next phrase
+	StartDialogue()      // Starts an interaction on the current phrase
+	IsExhausted() bool   // Returns true if the current dialogue tree is complete
+}
+
+// Player represents the player character
+type Player struct {
+	X         float64        // The current X world position of the player
+	Y         float64        // The current Y world position of the player
+	Animation bool           // Whether or not the player is in a special animation or the normal stand/walk cycle.
+	LastDir   Direction      // The last direction the player faced (never -1)
+	Sprite    Sprite         // The current sprite for the player
+	FrameNum  int            // The current frame of the sprite for the player
+	FrameDur  int            // The duration of the current frame of the sprite for the player
+	Health    int            // How much health the player has
+	MaxHealth int            // The most health the player can heal up to
+	Items     map[string]int // How many of each item the player has collected
+	Weapon    *Weapon        // The weapon the player has equipped
+	Dying     bool           // Whether the player died and the death sequence is playing
+	DeathTime int            // How many ticks of the death sequence have played
+	Hurt
+	Motion
+}
+
+type DialogueGraph struct {
+	Nodes    map[string]*DialogueNode
+	Edges    map[string][]DialogueEdge
+	NodeKey  string    // The current node of dialogue the player is on
+	RootKey  string    // The root node of the current dialogue tree
+	Finished bool      // Whether the last interaction finished on the current node, so the next one follows its connections
+	Vars     Variables // The variables the conditions of the dialogue check, shared by every dialogue of a game
+}
+
+type DialogueNode struct {
+	Phrase    string           // The phrase of dialogue
+	Options   []DialogueOption // The options on the node, or empty
+	RuneNum   int              // How many runes of the phrase are shown
+	OptionNum int              // Which of the shown options is selected
+	End       bool             // Whether or not to end the interaction after this node is completed.
+	Condition Condition        // The condition for the node to be entered
+	Set       Variables        // The variables set when the node is entered
+}
+
+// DialogueOption is an answer the player can pick on a node of dialogue
+type DialogueOption struct {
+	Text      string    // The text of the option
+	Next      string    // The node the option leads to
+	Condition Condition // The condition for the option to be shown
+}
+
+// DialogueEdge is a connection from one node of dialogue to the next
+type DialogueEdge struct {
+	Next      string    // The node the connection leads to
+	Condition Condition // The condition for the connection to be followed
+}
+
+// ShownOptions returns the options of a node whose conditions hold and whose next nodes can be entered
+func (g *DialogueGraph) ShownOptions(key string) []DialogueOption {
+	var options []DialogueOption
+	for _, o := range g.Nodes[key].Options {
+		if o.Condition.Holds(g.Vars) && g.Nodes[o.Next].Condition.Holds(g.Vars) {
+			options = append(options, o)
+		}
+	}
+	return options
+}
+
+// Follow returns the first connection of a node whose condition holds and whose next node can be entered, or false if there is none
+func (g *DialogueGraph) Follow(key string) (string, bool) {
+	for _, e := range g.Edges[key] {
+		if e.Condition.Holds(g.Vars) && g.Nodes[e.Next].Condition.Holds(g.Vars) {
+			return e.Next, true
+		}
+	}
+	return "", false
+}
+
+// Enter moves to a node and sets its variables
+func (g *DialogueGraph) Enter(key string) {
+	g.Nodes[g.NodeKey].RuneNum = 0
+	g.Nodes[g.NodeKey].OptionNum = 0
+	g.NodeKey = key
+	g.Finished = false
+	g.Vars.Set(g.Nodes[key].Set)
+}
+
+// Start enters the node an interaction starts on. If the last interaction finished, its connections are followed now,
+// so they can depend on what happened since.
+func (g *DialogueGraph) Start() {
+	key := g.NodeKey
+	if g.Finished {
+		if next, ok := g.Follow(key); ok {
+			key = next
+		}
+	}
+	g.Enter(key)
+}
+
+// Character represents an npc character
+type Character struct {
+	X              float64                   // The current X world position of the character
+	Y              float64                   // The current Y world position of the character
+	Animation      bool                      // Whether or not the character is in a special animation or the normal stand/walk cycle.
+	LastDir        Direction                 // The last direction the character faced (never -1)
+	Sprite         Sprite                    // The current sprite for the character
+	FrameNum       int                       // The current frame of the sprite for the character
+	DialogueGraphs map[string]*DialogueGraph // The dialogue graphs the character has
+	DialogueKey    string                    // The current dialogue graph the character has loaded
+	Motion
+}
+
+func (c *Character) Dialogue() string {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	node := graph.Nodes[graph.NodeKey]
+	return RunePrefix(node.Phrase, node.RuneNum)
+}
+
+func (c *Character) Options() [][]string {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	var options [][]string
+	for _, o := range graph.ShownOptions(graph.NodeKey) {
+		options = append(options, []string{o.Text, o.Next})
+	}
+	return options
+}
+
+func (c *Character) SelectOption(dir int) {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	node := graph.Nodes[graph.NodeKey]
+	node.OptionNum = Min(Max(node.OptionNum+dir, 0), len(graph.ShownOptions(graph.NodeKey))-1)
+}
+
+func (c *Character) SelectedOption() int {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	node := graph.Nodes[graph.NodeKey]
+	return node.OptionNum
+}
+
+func (c *Character) AdvanceRune() {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	node := graph.Nodes[graph.NodeKey]
+	node.RuneNum = NextCluster(node.Phrase, node.RuneNum)
+}
+
+func (c *Character) StartDialogue() {
+	c.DialogueGraphs[c.DialogueKey].Start()
+}
+
+func (c *Character) AdvancePhrase() {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	node := graph.Nodes[graph.NodeKey]
+	options := graph.ShownOptions(graph.NodeKey)
+	if len(options) > 0 {
+		graph.Enter(options[Min(node.OptionNum, len(options)-1)].Next)
+	} else if node.End {
+		// The connections of the last node are followed when the next interaction starts
+		node.RuneNum = 0
+		graph.Finished = true
+	} else if next, ok := graph.Follow(graph.NodeKey); ok {
+		graph.Enter(next)
+	}
+}
+
+func (c *Character) IsExhausted() bool {
+	graph := c.DialogueGraphs[c.DialogueKey]
+	return graph.Nodes[graph.NodeKey].End
+}
+
+// Enemy represents an enemy
+type Enemy struct {
+	X         float64   // The current X world position of the enemy
+	Y         float64   // The current Y world position of the enemy
+	Animation bool      // Whether or not the enemy is in a special animation or the normal stand/walk cycle.
+	LastDir   Direction // The last direction the enemy faced (never -1)
+	Sprite    Sprite    // The current sprite for the enemy
+	FrameNum  int       // The current frame of the sprite for the enemy
+	FrameDur  int       // The duration of the current frame of the sprite for the enemy
+	Type      string    // The type of enemy, which picks its loot table
+	Behavior  Behavior  // The active behavior of the enemy
+	Health    int       // How much health the enemy has. It dies when this reaches 0.
+	Dying     bool      // Whether the enemy was killed and is playing its death animation before it is removed
+	LastSwing int       // The swing of the player's weapon that last hit the enemy, so a swing only hits once
+	Hurt
+	Motion
+}
+
+// Weapon represents a weapon held by something
+type Weapon struct {
+	Sprite      Sprite       // The sprite for the weapon, facing east with its handle where it is held
+	Wielder     RenderTarget // The wielder of the weapon. The weapon is drawn relative to the wielder.
+	IsAttacking bool         // Whether or not the weapon is attacking and should be drawn.
+	Dir         Direction    // The direction the weapon is swung in
+	Damage      int          // How much health a hit takes from an enemy
+	Swing       int          // Counts the swings of the weapon, so each swing can hit an enemy once
+}
+
+// Projectile represents a projectile
+type Projectile struct {
+	X        float64   // The current X world position of the projectile
+	Y        float64   // The current Y world position of the projectile
+	Sprite   Sprite    // The current sprite for the projectile
+	FrameNum int       // The current frame of the sprite for the projectile
+	Dir      Direction // The direction the projection is travelling
+	IsEnemy  bool      // Whether or not the projecile is enemy or friendly
+	Spent    bool      // Whether the projectile hit something or left the world, and should be removed
+	Motion
+}
+
+// Pickup represents an item on the ground that the player collects by walking over it
+type Pickup struct {
+	X         float64 // The current X world position of the pickup
+	Y         float64 // The current Y world position of the pickup
+	Sprite    Sprite  // The current sprite for the pickup
+	FrameNum  int     // The current frame of the sprite for the pickup
+	Item      string  // The item the pickup gives
+	Amount    int     // How many of the item the pickup gives
+	Collected bool    // Whether the player collected the pickup, and it should be removed
+}
+
+// Doodad represents a static environmental item
+type Doodad struct {
+	X        int    // The current X screen offset of the doodad
+	Y        int    // The current Y screen offset of the doodad
+	Sprite   Sprite // The current sprite for the doodad
+	FrameNum int    // The current frame of the sprite for the doodad
+}
+
+// Tile represents a floor texture
+type Tile struct {
+	X        int    // The current X screen offset of the doodad
+	Y        int    // The current Y screen offset of the doodad
+	Sprite   Sprite // The current sprite for the doodad
+	FrameNum int    // The current frame of the sprite for the doodad
+	Collider bool   // Whether or not the tile can be collided with
+	Layer    int    // The layer the tile is on. Tiles on higher layers are drawn over tiles on lower layers.
+}
+
+// Sprite represents an image with a number of sub-frames in it to be rendered via rectangles
+type Sprite struct {
+	Key         string // The key of the sprite in Game.Sprites, used to save and load game elements
+	FrameWidth  int
+	FrameHeight int
+	FrameLen    int           // How many frames are in the sprite
+	FrameDur    int           // How many render frames to display a single frame of the sprite
+	Handles     []image.Point // An array of coordinates inside the sprite for attaching other sprites to
+	Image       Image
+}
+
+// SpriteJSON represents the json to be read from the sprite json file.
+type SpriteJSON struct {
+	FrameDur    int           `json:"frameDuration"`
+	FrameLen    int           `json:"frameLen"`
+	FrameHeight int           `json:"frameHeight"`
+	FrameWidth  int           `json:"frameWidth"`
+	Handles     []image.Point `json:"handles"`
+	Image       string        `json:"image"`
+}
+
+// DialogueJSON represents the json to be read from the dialogue json file.
+type DialogueJSON struct {
+	ID          string                   `json:"id"`
+	Phrase      string                   `json:"phrase"`
+	Options     []DialogueOptionJSON     `json:"options"`
+	Connections []DialogueConnectionJSON `json:"connections"`
+	End         bool                     `json:"end"`
+	Condition   string                   `json:"condition"` // The condition for the node to be entered, or "" to always enter it
+	Set         map[string]interface{}   `json:"set"`       // The variables to set when the node is entered
+}
+
+// DialogueOptionJSON represents an option of a dialogue node, either as ["text", "next"] or as an object with a condition
+type DialogueOptionJSON struct {
+	Text      string `json:"text"`
+	Next      string `json:"next"`
+	Condition string `json:"condition"`
+}
+
+// UnmarshalJSON reads an option from either a ["text", "next"] pair or an object
+func (o *DialogueOptionJSON) UnmarshalJSON(data []byte) error {
+	var pair []string
+	if err := json.Unmarshal(data, &pair); err == nil {
+		if len(pair) != 2 {
+			return fmt.Errorf("option must be a [text, next] pair, got %d strings", len(pair))
+		}
+		*o = DialogueOptionJSON{Text: pair[0], Next: pair[1]}
+		return nil
+	}
+	type option DialogueOptionJSON
+	return json.Unmarshal(data, (*option)(o))
+}
+
+// DialogueConnectionJSON represents a connection of a dialogue node, either as the id of the next node or as an object with a condition
+type DialogueConnectionJSON struct {
+	Next      string `json:"next"`
+	Condition string `json:"condition"`
+}
+
+// UnmarshalJSON reads a connection from either the id of the next node or an object
+func (c *DialogueConnectionJSON) UnmarshalJSON(data []byte) error {
+	var next string
+	if err := json.Unmarshal(data, &next); err == nil {
+		*c = DialogueConnectionJSON{Next: next}
+		return nil
+	}
+	type connection DialogueConnectionJSON
+	return json.Unmarshal(data, (*connection)(c))
+}
+
+// Step runs n updates of the game, stopping at the first error
+func (g *Game) Step(n int) error {
+	for i := 0; i < n; i++ {
+		if err := g.Update(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *Game) Update() error {
+	g.Input.Update()
+
+	if g.Input.IsJustReleased(QuickSave) {
+		if err := g.SaveSlot(QuickSaveSlot); err != nil {
+			log.Println(err)
+		}
+	} else if g.Input.IsJustReleased(QuickLoad) {
+		if err := g.LoadSlot(QuickSaveSlot); err != nil {
+			log.Println(err)
+		}
+	}
+
+	err := g.Scene().Update(g)
+	UpdateAudio(g)
+	return err
+}
@@ -0,0 +1,363 @@
+package game
+
+import (
+	"image"
+	"os"
+	"testing"
+)
+
+// newTestGame loads a game from the assets in the repository and a level fixture, with input played back from a script
+func newTestGame(t *testing.T, levelPath string, script ...[][]Action) *Game {
+	t.Helper()
+	var frames [][]Action
+	for _, s := range script {
+		frames = append(frames, s...)
+	}
+	g, err := LoadGame("..", levelPath, &ScriptedInput{Frames: frames})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// step runs n updates of the game
+func step(t *testing.T, g *Game, n int) {
+	t.Helper()
+	if err := g.Step(n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWalkingIntoStumpStopsPlayer(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json", Hold(60, MoveLeft))
+	stump := g.Tiles[len(g.Tiles)-1]
+	if stump.X != 64 || stump.Y != 128 {
+		t.Fatalf("expected the stump at (64,128), got (%v,%v)", stump.X, stump.Y)
+	}
+
+	step(t, g, 60)
+	player := g.Player.Hitbox(0, 0)
+	if player.Overlaps(stump.Hitbox(0, 0)) {
+		t.Fatalf("player %v walked into the stump %v", player, stump.Hitbox(0, 0))
+	}
+	if player.Min.X != stump.Hitbox(0, 0).Max.X {
+		t.Errorf("expected the player to stop against the stump at x %d, got %d", stump.Hitbox(0, 0).Max.X, player.Min.X)
+	}
+	if g.Player.Y != 128 {
+		t.Errorf("expected the player to stay at y 128, got %v", g.Player.Y)
+	}
+}
+
+func TestWalkingAwayFromStump(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json", Hold(10, MoveRight))
+	step(t, g, 10)
+	if g.Player.X != 130 {
+		t.Errorf("expected the player to walk 10 pixels to x 130, got %v", g.Player.X)
+	}
+	if g.Player.LastDir != East {
+		t.Errorf("expected the player to face east, got %v", g.Player.LastDir)
+	}
+}
+
+func TestWizardHitsPlayerInLine(t *testing.T) {
+	g := newTestGame(t, "testdata/wizard.json")
+	health := g.Player.Health
+
+	step(t, g, 1)
+	if len(g.Projectiles) != 1 || g.Projectiles[0].Dir != South {
+		t.Fatalf("expected the wizard to shoot a fireball south, got %+v", g.Projectiles)
+	}
+
+	step(t, g, 59)
+	if g.Player.Health != health-PlayerHitDamage {
+		t.Errorf("expected the player to be hit once and have %d health, got %d", health-PlayerHitDamage, g.Player.Health)
+	}
+	if len(g.Projectiles) != 0 {
+		t.Errorf("expected the fireball to be removed when it hit, got %d projectiles", len(g.Projectiles))
+	}
+}
+
+func TestProjectilesAreSpentLeavingLevel(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json")
+	// The stump level is 320 pixels wide, so the fireball leaves it after a few frames
+	g.Projectiles = []Projectile{{X: 310, Y: 40, Sprite: g.Sprites["fireballEast"], Motion: Motion{Speed: 3}, Dir: East, IsEnemy: true}}
+	step(t, g, 2)
+	if len(g.Projectiles) != 1 {
+		t.Fatalf("expected the fireball to still be in the level, got %v", g.Projectiles)
+	}
+	step(t, g, 3)
+	if len(g.Projectiles) != 0 {
+		t.Errorf("expected the fireball to be spent leaving the level, got %v", g.Projectiles)
+	}
+}
+
+func TestProjectilesOutsideRoomAreSuspended(t *testing.T) {
+	g := newTestGame(t, "../levels/rooms.json")
+	fireball := Projectile{Y: 40, Sprite: g.Sprites["fireballWest"], Motion: Motion{Speed: 3}, Dir: West, IsEnemy: true}
+	inRoom, outside := fireball, fireball
+	inRoom.X, outside.X = 200, 400
+	g.Projectiles = []Projectile{inRoom, outside}
+
+	step(t, g, 5)
+	if len(g.Projectiles) != 2 {
+		t.Fatalf("expected both fireballs to be kept, got %v", g.Projectiles)
+	}
+	if g.Projectiles[0].X != 185 {
+		t.Errorf("expected the fireball in the player's room to fly to x 185, got %v", g.Projectiles[0].X)
+	}
+	if g.Projectiles[1].X != 400 {
+		t.Errorf("expected the fireball in the next room to stay at x 400, got %v", g.Projectiles[1].X)
+	}
+}
+
+func TestProjectilesAreKeptLeavingRoom(t *testing.T) {
+	g := newTestGame(t, "../levels/rooms.json", Hold(300, MoveRight))
+	fireball := Projectile{Y: 40, Sprite: g.Sprites["fireballWest"], Motion: Motion{Speed: 1}, Dir: West, IsEnemy: true}
+	left, next := fireball, fireball
+	left.X, next.X = 200, 600
+	g.Projectiles = []Projectile{left, next}
+
+	// Walk right into the next room
+	for i := 0; g.Camera.Room.X == 0; i++ {
+		if i == 300 {
+			t.Fatal("expected the player to walk into the next room")
+		}
+		step(t, g, 1)
+	}
+	if len(g.Projectiles) < 2 {
+		t.Fatalf("expected both fireballs to be kept leaving the room, got %v", g.Projectiles)
+	}
+	x := g.Projectiles[0].X
+	if x >= 200 || g.Projectiles[1].X != 600 {
+		t.Fatalf("expected only the fireball in the first room to have flown, got x %v and %v", x, g.Projectiles[1].X)
+	}
+
+	// Once the camera is in the next room, its fireball flies and the one left behind is suspended
+	for g.Camera.IsScrolling() {
+		step(t, g, 1)
+	}
+	step(t, g, 10)
+	if g.Projectiles[0].X != x {
+		t.Errorf("expected the fireball in the room left behind to stay at x %v, got %v", x, g.Projectiles[0].X)
+	}
+	if g.Projectiles[1].X >= 600 {
+		t.Errorf("expected the fireball in the player's room to fly west, got x %v", g.Projectiles[1].X)
+	}
+}
+
+func TestSwordKillsWizard(t *testing.T) {
+	script := [][][]Action{Hold(1, MoveRight)}
+	for i := 0; i < DefaultEnemyHealth; i++ {
+		script = append(script, Hold(1, Attack), Hold(50))
+	}
+	g := newTestGame(t, "testdata/sword.json", script...)
+	// The wizard shoots back, so keep the player from being knocked out of reach
+	g.DamageRules[PlayerLayer] = DamageRule{Invulnerability: g.DamageRules[PlayerLayer].Invulnerability}
+
+	step(t, g, 3)
+	if r := g.Player.Weapon.Hitbox(0, 0); !r.Overlaps(g.Enemies[0].Hitbox(0, 0)) {
+		t.Fatalf("expected the sword hitbox %v to reach the wizard at %v", r, g.Enemies[0].Hitbox(0, 0))
+	}
+	step(t, g, 49)
+	if g.Enemies[0].Health != DefaultEnemyHealth-1 {
+		t.Fatalf("expected the swing to hit the wizard once and leave %d health, got %d", DefaultEnemyHealth-1, g.Enemies[0].Health)
+	}
+
+	step(t, g, 51*(DefaultEnemyHealth-2)+4)
+	if len(g.Enemies) != 1 || !g.Enemies[0].Dying {
+		t.Fatalf("expected the wizard to be dying after %d swings, got %+v", DefaultEnemyHealth, g.Enemies)
+	}
+	if g.Rolls != 1 {
+		t.Errorf("expected the wizard to roll its loot table once, got %d rolls", g.Rolls)
+	}
+	for _, c := range g.Colliders.Query(g.Enemies[0].Hitbox(0, 0)) {
+		if c == Collider(&g.Enemies[0]) {
+			t.Error("expected the dying wizard to stop blocking")
+		}
+	}
+
+	death := g.Sprites["enemyDeath"]
+	step(t, g, death.FrameLen*death.FrameDur)
+	if len(g.Enemies) != 0 {
+		t.Errorf("expected the wizard to be removed after its death animation, got %+v", g.Enemies)
+	}
+}
+
+func TestSwordTurnsWithSwing(t *testing.T) {
+	g := newTestGame(t, "testdata/sword.json")
+	w := g.Player.Weapon
+	w.Wielder = &g.Player
+	w.IsAttacking = true
+	// Only attack sprites have a hand to hold the sword in
+	g.Player.Sprite = g.Sprites["linkAttackEast"]
+	// The east-facing sword is 16x16, held 3 from its left and 8 from its top, so the blade reaches 13 past the hand
+	tests := map[Direction]image.Rectangle{
+		East:  image.Rect(-3, -8, 13, 8),
+		West:  image.Rect(-13, -8, 3, 8),
+		North: image.Rect(-8, -13, 8, 3),
+		South: image.Rect(-8, -3, 8, 13),
+	}
+	for dir, want := range tests {
+		w.Dir = dir
+		want = want.Add(w.Hand())
+		if r := w.Hitbox(0, 0); r != want {
+			t.Errorf("%v: expected the hitbox %v, got %v", dir, want, r)
+		}
+		if p := w.RenderPosition(); p != want.Min {
+			t.Errorf("%v: expected the turned sword to be drawn from %v, got %v", dir, want.Min, p)
+		}
+		// The corners of the image turned around the handle are the corners of the hitbox
+		handle := w.RenderHandle()
+		img := w.Sprite.Image.Bounds()
+		turned := w.Turn().ApplyRect(img.Sub(img.Min).Sub(handle)).Add(w.Hand())
+		if turned != want {
+			t.Errorf("%v: expected the drawn sword to cover %v, got %v", dir, want, turned)
+		}
+	}
+}
+
+func TestDeathAndRespawn(t *testing.T) {
+	g := newTestGame(t, "testdata/death.json", Hold(60+PlayerDeathDuration), Hold(1, MenuDown), Hold(1, MenuUp), Hold(1, Confirm), Hold(1))
+	g.Player.Health = 1
+
+	step(t, g, 60)
+	if !g.Player.Dying || g.Player.Health != 0 {
+		t.Fatalf("expected the fireball to kill the player, got health %d", g.Player.Health)
+	}
+	enemy := g.Enemies[0]
+	step(t, g, PlayerDeathDuration)
+	if _, ok := g.Scene().(*GameOverScene); !ok {
+		t.Fatal("expected the game over menu after the death sequence")
+	}
+	if g.Enemies[0].Behavior != enemy.Behavior || len(g.Projectiles) != 0 {
+		t.Errorf("expected the world to be frozen while dying, got %+v and %d projectiles", g.Enemies[0].Behavior, len(g.Projectiles))
+	}
+
+	step(t, g, 3)
+	if s, ok := g.Scene().(*GameOverScene); !ok || s.Menu.Selected != 0 {
+		t.Fatalf("expected the game over menu to still be open with continue selected, got %+v", g.Scene())
+	}
+	step(t, g, 1)
+	if len(g.Scenes) != 1 || g.Player.Dying {
+		t.Fatal("expected continuing to respawn the player")
+	}
+	if g.Player.Health != 50 {
+		t.Errorf("expected the level to restore 50 health, got %d", g.Player.Health)
+	}
+	if g.Player.X != 256 || g.Player.Y != 203 {
+		t.Errorf("expected to respawn at the checkpoint at (256,203), got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+	if b := g.Enemies[0].Behavior; b.Command != "" || b.Paused != 0 {
+		t.Errorf("expected the wizard's behavior to be reset, got %+v", b)
+	}
+	if len(g.Projectiles) != 0 {
+		t.Errorf("expected projectiles to be despawned, got %d", len(g.Projectiles))
+	}
+}
+
+func TestGameOverQuit(t *testing.T) {
+	g := newTestGame(t, "testdata/death.json", Hold(60+PlayerDeathDuration), Hold(1, MenuDown), Hold(1), Hold(1, Confirm), Hold(1))
+	g.Player.Health = 1
+	step(t, g, 60+PlayerDeathDuration+3)
+	if err := g.Update(); err != ErrQuit {
+		t.Errorf("expected quitting to return ErrQuit, got %v", err)
+	}
+}
+
+func TestReplayMatchesRecording(t *testing.T) {
+	script := [][][]Action{Hold(30, MoveLeft), Hold(20, MoveUp, MoveRight), Hold(10), Hold(5, Attack)}
+	g := newTestGame(t, "testdata/wizard.json", script...)
+	recorder, err := NewRecorder(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 90; i++ {
+		if err := recorder.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	replayer, err := NewReplayer(newTestGame(t, "testdata/wizard.json"), &recorder.Recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.Recording.Frames[42].Hash = "0000000000000000"
+	replayer, err = NewReplayer(newTestGame(t, "testdata/wizard.json"), &recorder.Recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = replayer.Verify()
+	if d, ok := err.(*Divergence); !ok || d.Frame != 42 {
+		t.Errorf("expected a divergence at frame 42, got %v", err)
+	}
+}
+
+func TestReplayKeepsRecordedSettings(t *testing.T) {
+	g := newTestGame(t, "../levels/field.tmj", Hold(1, Interact), Hold(120))
+	if err := g.SetLocale("fr"); err != nil {
+		t.Fatal(err)
+	}
+	g.Settings.Volumes[MusicChannel] = 3
+	// Stand just under the elder, so the recording talks to the elder in french
+	elder := g.Characters[0].Hitbox(0, 0)
+	g.Player.X = g.Characters[0].X
+	g.Player.Y += float64(elder.Max.Y - g.Player.Hitbox(0, 0).Min.Y)
+	recorder, err := NewRecorder(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 121; i++ {
+		if err := recorder.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.InteractionTarget == nil {
+		t.Fatal("expected the player to talk to the elder")
+	}
+
+	// The game is replayed in the default locale and volume, as if run without the flags the recording was made with
+	played := newTestGame(t, "../levels/field.tmj")
+	replayer, err := NewReplayer(played, &recorder.Recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if played.Settings != g.Settings {
+		t.Errorf("expected the recorded settings %+v, got %+v", g.Settings, played.Settings)
+	}
+	if err := replayer.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordingKeepsSlotsInMemory(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json", Hold(1, QuickSave), Hold(1), Hold(10, MoveLeft), Hold(1, QuickLoad), Hold(1))
+	x := g.Player.X
+	recorder, err := NewRecorder(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 14; i++ {
+		if err := recorder.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := g.Slots[QuickSaveSlot]; !ok || g.Player.X != x {
+		t.Errorf("expected the quick load to go back to the quick save at x %v, got x %v", x, g.Player.X)
+	}
+	if _, err := os.Stat(SlotPath(QuickSaveSlot)); !os.IsNotExist(err) {
+		t.Errorf("expected the recording to leave the save slots on disk alone, got %v", err)
+	}
+
+	// Replaying loads the quick save made during the replay, not one left over from the recording
+	replayer, err := NewReplayer(newTestGame(t, "testdata/stump.json"), &recorder.Recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := replayer.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
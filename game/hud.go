@@ -0,0 +1,79 @@
+package game
+
+import (
+	"image"
+	"strconv"
+)
+
+const (
+	HealthPerHeart = 20 // How much health each heart of the HUD stands for
+	HUDMargin      = 4  // How far the HUD is from the edge of the screen
+	HUDHeight      = 20 // The height of the HUD, which is the height of the weapon slot
+	HUDCounterGap  = 28 // How far the text of an item counter runs past its icon before the next counter
+)
+
+// HUDItems are the items the HUD always shows a counter for. Each item is drawn with the sprite of the same key.
+var HUDItems = []string{"rupee"}
+
+// HUDElement is a sprite or a line of text drawn in screen space as part of the HUD
+type HUDElement struct {
+	Image    Image       // The image to draw, or nil to draw the text
+	Text     string      // The text to draw if there is no image
+	Position image.Point // The top left of the image, or the left and vertical middle of the text, on the screen
+}
+
+// HUD lays out the player status drawn over the world: hearts for their health, the weapon they have equipped and
+// counters for their items. It is drawn along the top of the screen, and moves to the bottom while the dialogue box is
+// shown there.
+func HUD(g *Game) []HUDElement {
+	var hud []HUDElement
+	top := HUDMargin
+	if g.InteractionTarget != nil {
+		top = ScreenHeight - HUDMargin - HUDHeight
+	}
+	x := HUDMargin
+
+	for _, key := range Hearts(g.Player.Health, g.Player.MaxHealth) {
+		heart := g.Sprites[key]
+		hud = append(hud, HUDElement{Image: heart.Image, Position: image.Pt(x, top+(HUDHeight-heart.FrameHeight)/2)})
+		x += heart.FrameWidth + 1
+	}
+	if x > HUDMargin {
+		x += HUDMargin
+	}
+
+	slot := g.Sprites["weaponSlot"]
+	hud = append(hud, HUDElement{Image: slot.Image, Position: image.Pt(x, top)})
+	if w := g.Player.Weapon; w != nil && w.Sprite.Image != nil {
+		// Center the first frame of the weapon in the slot
+		p := image.Pt(x+(slot.FrameWidth-w.Sprite.FrameWidth)/2, top+(HUDHeight-w.Sprite.FrameHeight)/2)
+		hud = append(hud, HUDElement{Image: w.Sprite.Frame(0), Position: p})
+	}
+	x += slot.FrameWidth + HUDMargin
+
+	for _, item := range HUDItems {
+		icon := g.Sprites[item]
+		hud = append(hud, HUDElement{Image: icon.Image, Position: image.Pt(x, top+(HUDHeight-icon.FrameHeight)/2)})
+		x += icon.FrameWidth + 2
+		hud = append(hud, HUDElement{Text: strconv.Itoa(g.Player.Items[item]), Position: image.Pt(x, top+HUDHeight/2)})
+		x += HUDCounterGap
+	}
+	return hud
+}
+
+// Hearts returns the sprite key of each heart for an amount of health, with a heart for every HealthPerHeart of the max
+// health. A heart that is only partly filled is drawn half full, so the player never looks empty while alive.
+func Hearts(health, maxHealth int) []string {
+	var hearts []string
+	for i := 0; i*HealthPerHeart < maxHealth; i++ {
+		switch left := health - i*HealthPerHeart; {
+		case left >= HealthPerHeart:
+			hearts = append(hearts, "heart")
+		case left > 0:
+			hearts = append(hearts, "heartHalf")
+		default:
+			hearts = append(hearts, "heartEmpty")
+		}
+	}
+	return hearts
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHearts(t *testing.T) {
+	tests := []struct {
+		health, maxHealth int
+		want              []string
+	}{
+		{100, 100, []string{"heart", "heart", "heart", "heart", "heart"}},
+		{50, 100, []string{"heart", "heart", "heartHalf", "heartEmpty", "heartEmpty"}},
+		{1, 60, []string{"heartHalf", "heartEmpty", "heartEmpty"}},
+		{0, 50, []string{"heartEmpty", "heartEmpty", "heartEmpty"}},
+	}
+	for _, tt := range tests {
+		if got := Hearts(tt.health, tt.maxHealth); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Hearts(%d, %d) = %v, want %v", tt.health, tt.maxHealth, got, tt.want)
+		}
+	}
+}
+
+func TestHitTakesHalfHeart(t *testing.T) {
+	want := []string{"heart", "heart", "heart", "heart", "heartHalf"}
+	if got := Hearts(100-PlayerHitDamage, 100); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected a hit to take half of the last heart, got %v", got)
+	}
+	want = []string{"heart", "heart", "heart", "heart", "heartEmpty"}
+	if got := Hearts(100-2*PlayerHitDamage, 100); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected two hits to take the whole last heart, got %v", got)
+	}
+}
+
+func TestHUDMovesForDialogue(t *testing.T) {
+	g := &Game{
+		Player: Player{Health: 30, MaxHealth: 40, Items: map[string]int{"rupee": 12}},
+		Sprites: map[string]Sprite{
+			"heart":      {FrameWidth: 7, FrameHeight: 6},
+			"heartHalf":  {FrameWidth: 7, FrameHeight: 6},
+			"weaponSlot": {FrameWidth: 20, FrameHeight: 20},
+			"rupee":      {FrameWidth: 8, FrameHeight: 14},
+		},
+	}
+	hud := HUD(g)
+	var counter *HUDElement
+	for i := range hud {
+		if hud[i].Text != "" {
+			counter = &hud[i]
+		}
+		if hud[i].Position.Y < HUDMargin || hud[i].Position.Y > HUDMargin+HUDHeight {
+			t.Errorf("expected the HUD to be along the top of the screen, got an element at %v", hud[i].Position)
+		}
+	}
+	if counter == nil || counter.Text != "12" {
+		t.Fatalf("expected a counter of 12 rupees, got %+v", hud)
+	}
+
+	g.InteractionTarget = &Character{}
+	for _, e := range HUD(g) {
+		if e.Position.Y < ScreenHeight-HUDMargin-HUDHeight {
+			t.Errorf("expected the HUD to move to the bottom of the screen for the dialogue box, got an element at %v", e.Position)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+)
+
+// Image is a sprite image that frames can be cut out of.
+// The main package loads *ebiten.Image values to draw, and headless games use decoded images, which both satisfy it.
+type Image interface {
+	image.Image
+	SubImage(r image.Rectangle) image.Image
+}
+
+// LoadImage loads the image file at path. It decodes the file by default, and the main package replaces it to load ebiten images.
+var LoadImage = DecodeImageFile
+
+// DecodeImageFile decodes an image file without a graphics device
+func DecodeImageFile(path string) (Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	sub, ok := img.(Image)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported image type %T", path, img)
+	}
+	return sub, nil
+}
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Action is a logical input that gameplay code reacts to, independent of the device or key it came from
+type Action int
+
+const (
+	MoveUp Action = iota
+	MoveDown
+	MoveLeft
+	MoveRight
+	Attack
+	Interact
+	Confirm
+	MenuLeft
+	MenuRight
+	MenuUp
+	MenuDown
+	QuickSave
+	QuickLoad
+	Inventory
+	Pause
+	ActionCount // The number of actions, not an action itself
+)
+
+// ActionNames are the names of actions used in bindings files
+var ActionNames = map[string]Action{
+	"moveUp":    MoveUp,
+	"moveDown":  MoveDown,
+	"moveLeft":  MoveLeft,
+	"moveRight": MoveRight,
+	"attack":    Attack,
+	"interact":  Interact,
+	"confirm":   Confirm,
+	"menuLeft":  MenuLeft,
+	"menuRight": MenuRight,
+	"menuUp":    MenuUp,
+	"menuDown":  MenuDown,
+	"quickSave": QuickSave,
+	"quickLoad": QuickLoad,
+	"inventory": Inventory,
+	"pause":     Pause,
+}
+
+func (a Action) String() string {
+	for name, v := range ActionNames {
+		if v == a {
+			return name
+		}
+	}
+	return fmt.Sprintf("Action(%d)", int(a))
+}
+
+// Input is a source of actions. Update is called once at the start of every game update, and the other methods report the state for that update.
+type Input interface {
+	Update()                    // Reads the state of every action for the current frame
+	IsPressed(Action) bool      // Returns true if the action is held down
+	IsJustPressed(Action) bool  // Returns true if the action started being held down this frame
+	IsJustReleased(Action) bool // Returns true if the action stopped being held down this frame
+	PressDuration(Action) int   // Returns how many frames the action has been held down, or 0
+}
+
+// ActionState tracks how long every action has been held down from whether it is held down each frame.
+// Input implementations embed it and call Set for every action in Update.
+type ActionState struct {
+	Durations [ActionCount]int  // How many frames each action has been held down
+	Released  [ActionCount]bool // Whether each action was released this frame
+}
+
+// Set records whether an action is held down in the current frame
+func (s *ActionState) Set(a Action, pressed bool) {
+	if pressed {
+		s.Durations[a]++
+		s.Released[a] = false
+	} else {
+		s.Released[a] = s.Durations[a] > 0
+		s.Durations[a] = 0
+	}
+}
+
+func (s *ActionState) IsPressed(a Action) bool {
+	return s.Durations[a] > 0
+}
+
+func (s *ActionState) IsJustPressed(a Action) bool {
+	return s.Durations[a] == 1
+}
+
+func (s *ActionState) IsJustReleased(a Action) bool {
+	return s.Released[a]
+}
+
+func (s *ActionState) PressDuration(a Action) int {
+	return s.Durations[a]
+}
+
+// BindingsJSON represents the json to be read from the bindings json file.
+// Each device maps action names to the names of the inputs bound to them.
+type BindingsJSON struct {
+	Keyboard        map[string][]string `json:"keyboard"`
+	Gamepad         map[string][]string `json:"gamepad"`
+	GamepadDeadzone float64             `json:"gamepadDeadzone"` // How far a stick has to be pushed from the center to count, from 0 to 1
+}
+
+// ReadBindings reads a bindings json file
+func ReadBindings(path string) (*BindingsJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonBindings BindingsJSON
+	if err := json.Unmarshal(data, &jsonBindings); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+	return &jsonBindings, nil
+}
+
+// CombinedInput merges several sources of actions into one. An action is held down while it is held down on any of the inputs,
+// so rules like the most recently pressed direction winning work the same across devices.
+type CombinedInput struct {
+	ActionState
+	Inputs []Input
+}
+
+func (c *CombinedInput) Update() {
+	for _, in := range c.Inputs {
+		in.Update()
+	}
+	for a := Action(0); a < ActionCount; a++ {
+		pressed := false
+		for _, in := range c.Inputs {
+			if in.IsPressed(a) {
+				pressed = true
+				break
+			}
+		}
+		c.Set(a, pressed)
+	}
+}
+
+// ScriptedInput plays back a fixed list of held down actions, one entry per frame, so tests and tools can drive the game.
+// Once the script runs out nothing is held down.
+type ScriptedInput struct {
+	ActionState
+	Frames [][]Action // The actions held down on each frame
+	Frame  int        // The next frame of the script to play
+}
+
+func (s *ScriptedInput) Update() {
+	var held []Action
+	if s.Frame < len(s.Frames) {
+		held = s.Frames[s.Frame]
+	}
+	s.Frame++
+	for a := Action(0); a < ActionCount; a++ {
+		pressed := false
+		for _, h := range held {
+			if h == a {
+				pressed = true
+			}
+		}
+		s.Set(a, pressed)
+	}
+}
+
+// Hold returns script frames that hold the actions down for a number of frames. No actions holds nothing down, which waits.
+func Hold(frames int, actions ...Action) [][]Action {
+	script := make([][]Action, frames)
+	for i := range script {
+		script[i] = actions
+	}
+	return script
+}
+
+// IsOtherDirectionJustReleased checks if one of the three movement actions other than the one passed in was just released
+// This is used to reset the walk cycle animation for a new direction
+func IsOtherDirectionJustReleased(in Input, a Action) bool {
+	for _, move := range []Action{MoveLeft, MoveRight, MoveUp, MoveDown} {
+		if move != a && in.IsJustReleased(move) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLeastKeyPressDuration checks if a movement action is the most recently pressed
+// This is used to set the correct walk direction if the player is holding down multiple walk buttons at once and releases one of them
+func IsLeastKeyPressDuration(in Input, a Action) bool {
+	d := in.PressDuration(a)
+	if d == 0 {
+		return false
+	}
+	for _, move := range []Action{MoveLeft, MoveRight, MoveUp, MoveDown} {
+		other := in.PressDuration(move)
+		if move != a && other != 0 && d >= other {
+			return false
+		}
+	}
+	return true
+}
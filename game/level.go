@@ -0,0 +1,385 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+)
+
+const (
+	DefaultEnemySpeed  = 1 // How many pixels an enemy walks per tick if its level doesn't say
+	DefaultEnemyHealth = 3 // How much health an enemy starts with if its level doesn't say
+)
+
+// Level represents a room of the game world as it was loaded from a level file
+type Level struct {
+	Name           string            // The name of the level
+	Path           string            // The file the level was loaded from
+	Spawn          image.Point       // Where the player is placed when entering the level
+	Bounds         image.Rectangle   // The extent of the world the camera may show
+	Camera         CameraMode        // How the camera moves through the level
+	RespawnEnemies bool              // Whether enemies are despawned when leaving a room and respawned when entering it in room mode
+	RespawnHealth  int               // How much health the player respawns with after dying, or 0 for all of it
+	Checkpoints    []image.Rectangle // The areas that move the respawn point of the player to them when walked into
+	Music          string            // The key of the music that loops while in the level, or "" for none
+	Tiles          []Tile
+	Doodads        []Doodad
+	Characters     []Character
+	Enemies        []Enemy
+}
+
+// LevelJSON represents the json to be read from a level json file.
+type LevelJSON struct {
+	Name           string               `json:"name"`
+	Spawn          *PointJSON           `json:"spawn"`
+	Width          int                  `json:"width"`  // The width of the world, or 0 to fit the tiles
+	Height         int                  `json:"height"` // The height of the world, or 0 to fit the tiles
+	Camera         string               `json:"camera"` // "follow" or "room"
+	RespawnEnemies bool                 `json:"respawnEnemies"`
+	RespawnHealth  int                  `json:"respawnHealth"`
+	Checkpoints    []LevelAreaJSON      `json:"checkpoints"`
+	Music          string               `json:"music"`
+	Fills          []LevelFillJSON      `json:"fills"`
+	Tiles          []LevelTileJSON      `json:"tiles"`
+	Doodads        []LevelEntityJSON    `json:"doodads"`
+	Characters     []LevelCharacterJSON `json:"characters"`
+	Enemies        []LevelEnemyJSON     `json:"enemies"`
+}
+
+// PointJSON represents a required position in a level json file. Pointers are used so missing fields can be reported.
+type PointJSON struct {
+	X *int `json:"x"`
+	Y *int `json:"y"`
+}
+
+// LevelAreaJSON represents a rectangle in a level json file, from its top left corner
+type LevelAreaJSON struct {
+	PointJSON
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// Rect returns the rectangle of the area, or an error naming the first missing or invalid field
+func (a *LevelAreaJSON) Rect() (image.Rectangle, error) {
+	p, err := a.Point()
+	if err != nil {
+		return image.Rectangle{}, err
+	}
+	if a.Width <= 0 {
+		return image.Rectangle{}, fmt.Errorf("width: must be positive, got %d", a.Width)
+	}
+	if a.Height <= 0 {
+		return image.Rectangle{}, fmt.Errorf("height: must be positive, got %d", a.Height)
+	}
+	return image.Rect(p.X, p.Y, p.X+a.Width, p.Y+a.Height), nil
+}
+
+// LevelEntityJSON represents a single sprite placed in the level at the X and Y of its "feet"
+type LevelEntityJSON struct {
+	PointJSON
+	Sprite string `json:"sprite"`
+}
+
+// LevelFillJSON represents a grid of identical tiles, starting at X and Y and stepping by the sprite size
+type LevelFillJSON struct {
+	LevelEntityJSON
+	Columns  int  `json:"columns"`
+	Rows     int  `json:"rows"`
+	Collider bool `json:"collider"`
+	Layer    int  `json:"layer"`
+}
+
+// LevelTileJSON represents a single tile in a level json file
+type LevelTileJSON struct {
+	LevelEntityJSON
+	Collider bool `json:"collider"`
+	Layer    int  `json:"layer"`
+}
+
+// LevelCharacterJSON represents an npc character in a level json file
+type LevelCharacterJSON struct {
+	LevelEntityJSON
+	Dialogue string `json:"dialogue"` // The key of the dialogue graph the character starts with
+}
+
+// LevelEnemyJSON represents an enemy in a level json file
+type LevelEnemyJSON struct {
+	LevelEntityJSON
+	Pause  int     `json:"pause"`  // The wait time between attacks
+	Speed  float64 `json:"speed"`  // How many pixels the enemy walks per tick, or 0 for DefaultEnemySpeed
+	Health int     `json:"health"` // How much health the enemy starts with, or 0 for DefaultEnemyHealth
+	Type   string  `json:"type"`   // The type of enemy, which picks its loot table
+}
+
+// LoadLevel reads a level file and resolves its sprite and dialogue keys.
+// Errors name the file, the entity and the field that is broken.
+// Tiled .tmx and .tmj maps are loaded with LoadTiledMap.
+func LoadLevel(path string, sprites map[string]Sprite, dialogueGraphs map[string]*DialogueGraph) (*Level, error) {
+	switch filepath.Ext(path) {
+	case ".tmx", ".tmj":
+		return LoadTiledMap(path, sprites, dialogueGraphs)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonLevel LevelJSON
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&jsonLevel); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+
+	level, err := jsonLevel.Level(sprites, dialogueGraphs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	level.Path = path
+	return level, nil
+}
+
+// Level converts the json representation of a level into game elements
+func (l *LevelJSON) Level(sprites map[string]Sprite, dialogueGraphs map[string]*DialogueGraph) (*Level, error) {
+	level := Level{Name: l.Name, RespawnEnemies: l.RespawnEnemies, Music: l.Music}
+
+	if l.RespawnHealth < 0 {
+		return nil, fmt.Errorf("respawnHealth: must not be negative, got %d", l.RespawnHealth)
+	}
+	level.RespawnHealth = l.RespawnHealth
+
+	camera, err := ParseCameraMode(l.Camera)
+	if err != nil {
+		return nil, fmt.Errorf("camera: %w", err)
+	}
+	level.Camera = camera
+
+	if l.Spawn == nil {
+		return nil, errors.New("spawn: missing field")
+	}
+	spawn, err := l.Spawn.Point()
+	if err != nil {
+		return nil, fmt.Errorf("spawn.%w", err)
+	}
+	level.Spawn = spawn
+
+	for i, v := range l.Checkpoints {
+		r, err := v.Rect()
+		if err != nil {
+			return nil, fmt.Errorf("checkpoints[%d].%w", i, err)
+		}
+		level.Checkpoints = append(level.Checkpoints, r)
+	}
+
+	for i, v := range l.Fills {
+		sprite, p, err := v.Resolve(sprites)
+		if err != nil {
+			return nil, fmt.Errorf("fills[%d].%w", i, err)
+		}
+		if v.Columns < 1 {
+			return nil, fmt.Errorf("fills[%d].columns: must be at least 1, got %d", i, v.Columns)
+		}
+		if v.Rows < 1 {
+			return nil, fmt.Errorf("fills[%d].rows: must be at least 1, got %d", i, v.Rows)
+		}
+		for x := 0; x < v.Columns; x++ {
+			for y := 0; y < v.Rows; y++ {
+				level.Tiles = append(level.Tiles, Tile{
+					X:        p.X + x*sprite.FrameWidth,
+					Y:        p.Y + y*sprite.FrameHeight,
+					Sprite:   sprite,
+					Collider: v.Collider,
+					Layer:    v.Layer,
+				})
+			}
+		}
+	}
+
+	for i, v := range l.Tiles {
+		sprite, p, err := v.Resolve(sprites)
+		if err != nil {
+			return nil, fmt.Errorf("tiles[%d].%w", i, err)
+		}
+		level.Tiles = append(level.Tiles, Tile{
+			X:        p.X,
+			Y:        p.Y,
+			Sprite:   sprite,
+			Collider: v.Collider,
+			Layer:    v.Layer,
+		})
+	}
+
+	for i, v := range l.Doodads {
+		sprite, p, err := v.Resolve(sprites)
+		if err != nil {
+			return nil, fmt.Errorf("doodads[%d].%w", i, err)
+		}
+		level.Doodads = append(level.Doodads, Doodad{
+			X:      p.X,
+			Y:      p.Y,
+			Sprite: sprite,
+		})
+	}
+
+	for i, v := range l.Characters {
+		sprite, p, err := v.Resolve(sprites)
+		if err != nil {
+			return nil, fmt.Errorf("characters[%d].%w", i, err)
+		}
+		if v.Dialogue == "" {
+			return nil, fmt.Errorf("characters[%d].dialogue: missing field", i)
+		}
+		if _, ok := dialogueGraphs[v.Dialogue]; !ok {
+			return nil, fmt.Errorf("characters[%d].dialogue: unknown dialogue %q", i, v.Dialogue)
+		}
+		level.Characters = append(level.Characters, Character{
+			X:              float64(p.X),
+			Y:              float64(p.Y),
+			Sprite:         sprite,
+			DialogueGraphs: dialogueGraphs,
+			DialogueKey:    v.Dialogue,
+		})
+	}
+
+	for i, v := range l.Enemies {
+		sprite, p, err := v.Resolve(sprites)
+		if err != nil {
+			return nil, fmt.Errorf("enemies[%d].%w", i, err)
+		}
+		if v.Pause < 0 {
+			return nil, fmt.Errorf("enemies[%d].pause: must not be negative, got %d", i, v.Pause)
+		}
+		if v.Speed < 0 {
+			return nil, fmt.Errorf("enemies[%d].speed: must not be negative, got %v", i, v.Speed)
+		}
+		speed := v.Speed
+		if speed == 0 {
+			speed = DefaultEnemySpeed
+		}
+		if v.Health < 0 {
+			return nil, fmt.Errorf("enemies[%d].health: must not be negative, got %d", i, v.Health)
+		}
+		health := v.Health
+		if health == 0 {
+			health = DefaultEnemyHealth
+		}
+		level.Enemies = append(level.Enemies, Enemy{
+			X:      float64(p.X),
+			Y:      float64(p.Y),
+			Sprite: sprite,
+			Behavior: Behavior{
+				Pause: v.Pause,
+			},
+			Type:   v.Type,
+			Health: health,
+			Motion: Motion{Speed: speed},
+		})
+	}
+
+	if l.Width < 0 || l.Height < 0 {
+		return nil, fmt.Errorf("width, height: must not be negative, got %dx%d", l.Width, l.Height)
+	}
+	level.Bounds = level.TileExtents()
+	if l.Width > 0 {
+		level.Bounds.Min.X, level.Bounds.Max.X = 0, l.Width
+	}
+	if l.Height > 0 {
+		level.Bounds.Min.Y, level.Bounds.Max.Y = 0, l.Height
+	}
+
+	return &level, nil
+}
+
+// TileExtents returns the smallest rectangle containing every tile of the level
+func (l *Level) TileExtents() image.Rectangle {
+	var r image.Rectangle
+	for _, t := range l.Tiles {
+		r = r.Union(image.Rect(t.X-t.Sprite.FrameWidth/2, t.Y-t.Sprite.FrameHeight, t.X+t.Sprite.FrameWidth/2, t.Y))
+	}
+	return r
+}
+
+// Point returns the position, or an error naming the first missing coordinate
+func (p *PointJSON) Point() (image.Point, error) {
+	if p.X == nil {
+		return image.Point{}, errors.New("x: missing field")
+	}
+	if p.Y == nil {
+		return image.Point{}, errors.New("y: missing field")
+	}
+	return image.Pt(*p.X, *p.Y), nil
+}
+
+// Resolve looks up the sprite of the entity and returns it with the entity position
+func (e *LevelEntityJSON) Resolve(sprites map[string]Sprite) (Sprite, image.Point, error) {
+	if e.Sprite == "" {
+		return Sprite{}, image.Point{}, errors.New("sprite: missing field")
+	}
+	sprite, ok := sprites[e.Sprite]
+	if !ok {
+		return Sprite{}, image.Point{}, fmt.Errorf("sprite: unknown sprite %q", e.Sprite)
+	}
+	p, err := e.Point()
+	if err != nil {
+		return Sprite{}, image.Point{}, err
+	}
+	return sprite, p, nil
+}
+
+// EnterLevel replaces the world with a fresh copy of the level and places the player at its spawn point
+func (g *Game) EnterLevel(l *Level) {
+	g.Level = l
+	g.Tiles = append([]Tile(nil), l.Tiles...)
+	g.Doodads = append([]Doodad(nil), l.Doodads...)
+	g.Characters = append([]Character(nil), l.Characters...)
+	g.Enemies = append([]Enemy(nil), l.Enemies...)
+	g.Projectiles = nil
+	g.Pickups = nil
+	g.InteractionTarget = nil
+	g.EnemyCollision = nil
+	g.ProjectileCollision = nil
+	g.Player.X = float64(l.Spawn.X)
+	g.Player.Y = float64(l.Spawn.Y)
+	g.Player.VX, g.Player.VY = 0, 0
+	g.Checkpoint = l.Spawn
+	g.Camera.Bounds = l.Bounds
+	g.Camera.Mode = l.Camera
+	g.IndexColliders()
+	ResetCamera(g)
+}
+
+// LeaveRoom despawns the enemies of the room the player is leaving if the level respawns them.
+// Its projectiles are kept, suspended like the enemies of other rooms until the player comes back.
+func LeaveRoom(g *Game, room image.Point) {
+	g.EnemyCollision = nil
+	g.ProjectileCollision = nil
+	if g.Level.RespawnEnemies {
+		RemoveEnemiesIn(g, g.Camera.RoomRect(room))
+		g.IndexColliders()
+	}
+}
+
+// EnterRoom respawns the enemies of the room the player is entering from the level data if the level respawns them
+func EnterRoom(g *Game, room image.Point) {
+	if !g.Level.RespawnEnemies {
+		return
+	}
+	rect := g.Camera.RoomRect(room)
+	RemoveEnemiesIn(g, rect)
+	for _, e := range g.Level.Enemies {
+		if image.Pt(Pixel(e.X), Pixel(e.Y)).In(rect) {
+			g.Enemies = append(g.Enemies, e)
+		}
+	}
+	g.IndexColliders()
+}
+
+// RemoveEnemiesIn removes every enemy standing inside of a world rectangle
+func RemoveEnemiesIn(g *Game, rect image.Rectangle) {
+	g.Enemies = Remove(g.Enemies, func(e *Enemy) bool { return image.Pt(Pixel(e.X), Pixel(e.Y)).In(rect) })
+}
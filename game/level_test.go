@@ -0,0 +1,59 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// loadLevelAssets loads the sprites and dialogue of the game that levels refer to
+func loadLevelAssets(t *testing.T) (map[string]Sprite, map[string]*DialogueGraph) {
+	t.Helper()
+	sprites, err := LoadSprites("../sprites/sprites.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphs, err := LoadDialogueGraphs("../dialogue/dialogue.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sprites, graphs
+}
+
+func TestLevelErrors(t *testing.T) {
+	sprites, graphs := loadLevelAssets(t)
+	tests := []struct {
+		name  string
+		level string
+		want  string
+	}{
+		{"missing spawn", `{}`, "spawn: missing field"},
+		{"missing spawn x", `{"spawn": {"y": 1}}`, "spawn.x: missing field"},
+		{"missing spawn y", `{"spawn": {"x": 1}}`, "spawn.y: missing field"},
+		{"unknown camera", `{"spawn": {"x": 1, "y": 1}, "camera": "orbit"}`, `camera: unknown camera mode "orbit"`},
+		{"negative respawn health", `{"spawn": {"x": 1, "y": 1}, "respawnHealth": -1}`, "respawnHealth: must not be negative, got -1"},
+		{"flat checkpoint", `{"spawn": {"x": 1, "y": 1}, "checkpoints": [{"x": 1, "y": 1, "width": 8}]}`, "checkpoints[0].height: must be positive, got 0"},
+		{"missing fill sprite", `{"spawn": {"x": 1, "y": 1}, "fills": [{"x": 1, "y": 1, "columns": 1, "rows": 1}]}`, "fills[0].sprite: missing field"},
+		{"bad fill columns", `{"spawn": {"x": 1, "y": 1}, "fills": [{"sprite": "grass", "x": 1, "y": 1, "rows": 1}]}`, "fills[0].columns: must be at least 1, got 0"},
+		{"bad fill rows", `{"spawn": {"x": 1, "y": 1}, "fills": [{"sprite": "grass", "x": 1, "y": 1, "columns": 2, "rows": -1}]}`, "fills[0].rows: must be at least 1, got -1"},
+		{"unknown tile sprite", `{"spawn": {"x": 1, "y": 1}, "tiles": [{"sprite": "grass", "x": 1, "y": 1}, {"sprite": "lava", "x": 1, "y": 1}]}`, `tiles[1].sprite: unknown sprite "lava"`},
+		{"missing doodad y", `{"spawn": {"x": 1, "y": 1}, "doodads": [{"sprite": "tree", "x": 1}]}`, "doodads[0].y: missing field"},
+		{"missing character dialogue", `{"spawn": {"x": 1, "y": 1}, "characters": [{"sprite": "elderStandSouth", "x": 1, "y": 1}]}`, "characters[0].dialogue: missing field"},
+		{"unknown character dialogue", `{"spawn": {"x": 1, "y": 1}, "characters": [{"sprite": "elderStandSouth", "x": 1, "y": 1, "dialogue": "wizard"}]}`, `characters[0].dialogue: unknown dialogue "wizard"`},
+		{"missing enemy sprite", `{"spawn": {"x": 1, "y": 1}, "enemies": [{"x": 1, "y": 1}]}`, "enemies[0].sprite: missing field"},
+		{"missing enemy x", `{"spawn": {"x": 1, "y": 1}, "enemies": [{"sprite": "skeletonWizardStandSouth", "y": 1}]}`, "enemies[0].x: missing field"},
+		{"negative enemy speed", `{"spawn": {"x": 1, "y": 1}, "enemies": [{"sprite": "skeletonWizardStandSouth", "x": 1, "y": 1, "speed": -1}]}`, "enemies[0].speed: must not be negative, got -1"},
+		{"negative width", `{"spawn": {"x": 1, "y": 1}, "width": -320}`, "width, height: must not be negative, got -320x0"},
+	}
+	for _, tt := range tests {
+		var l LevelJSON
+		if err := json.Unmarshal([]byte(tt.level), &l); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		_, err := l.Level(sprites, graphs)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		} else if err.Error() != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, err)
+		}
+	}
+}
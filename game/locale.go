@@ -0,0 +1,248 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultLocale is the language of dialogue.json and of the text in the code, which other locales fall back to
+const DefaultLocale = "en"
+
+// Locale is the text of a language other than the default. Missing text falls back to the default language.
+type Locale struct {
+	UI       map[string]string                  // Menu text keyed by its text in the default language
+	Dialogue map[string]map[string]DialogueText // Dialogue text keyed by dialogue and node id
+}
+
+// DialogueText is the text of a dialogue node in a locale
+type DialogueText struct {
+	Phrase  string   `json:"phrase"`  // The phrase, or "" to fall back to the default language
+	Options []string `json:"options"` // The text of each option in order, or "" to fall back to the default language
+}
+
+// LoadLocales reads the locales in the subdirectories of the dialogue directory, named after their language like fr.
+// Each has a string table of dialogue keyed by node id in dialogue.json and one of menu text in ui.json, and either can be left out.
+// The default locale is made from the text of the dialogue graphs, so other locales can fall back to it, and has no directory.
+func LoadLocales(dir string, dialogueGraphs map[string]*DialogueGraph) (map[string]*Locale, error) {
+	locales := map[string]*Locale{DefaultLocale: DefaultLocaleOf(dialogueGraphs)}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		// The default locale is the text of dialogue.json and the code, so a directory for it would replace that text
+		if name == DefaultLocale {
+			return nil, fmt.Errorf("%s: the default locale %s can't be changed, edit dialogue.json instead", filepath.Join(dir, name), DefaultLocale)
+		}
+		locale := &Locale{UI: map[string]string{}, Dialogue: map[string]map[string]DialogueText{}}
+		if err := readJSON(filepath.Join(dir, name, "ui.json"), &locale.UI); err != nil {
+			return nil, err
+		}
+		path := filepath.Join(dir, name, "dialogue.json")
+		if err := readJSON(path, &locale.Dialogue); err != nil {
+			return nil, err
+		}
+
+		// Text for dialogue that doesn't exist is most likely a typo in an id
+		for k, texts := range locale.Dialogue {
+			graph, ok := dialogueGraphs[k]
+			if !ok {
+				return nil, fmt.Errorf("%s: %s: unknown dialogue", path, k)
+			}
+			for id, text := range texts {
+				node, ok := graph.Nodes[id]
+				if !ok {
+					return nil, fmt.Errorf("%s: %s.%s: unknown node", path, k, id)
+				}
+				if len(text.Options) > len(node.Options) {
+					return nil, fmt.Errorf("%s: %s.%s.options: %d options for a node with %d", path, k, id, len(text.Options), len(node.Options))
+				}
+			}
+		}
+		locales[name] = locale
+	}
+	return locales, nil
+}
+
+// readJSON decodes a json file into v, leaving v as it was if the file doesn't exist
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return JSONError(path, data, err)
+	}
+	return nil
+}
+
+// DefaultLocaleOf returns the default locale made from the text of dialogue graphs
+func DefaultLocaleOf(dialogueGraphs map[string]*DialogueGraph) *Locale {
+	locale := &Locale{UI: map[string]string{}, Dialogue: map[string]map[string]DialogueText{}}
+	for k, graph := range dialogueGraphs {
+		locale.Dialogue[k] = map[string]DialogueText{}
+		for id, node := range graph.Nodes {
+			text := DialogueText{Phrase: node.Phrase}
+			for _, o := range node.Options {
+				text.Options = append(text.Options, o.Text)
+			}
+			locale.Dialogue[k][id] = text
+		}
+	}
+	return locale
+}
+
+// LocaleNames returns the names of the loaded locales in order, starting with the default
+func (g *Game) LocaleNames() []string {
+	var names []string
+	for name := range g.Locales {
+		if name != DefaultLocale {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return append([]string{DefaultLocale}, names...)
+}
+
+// SetLocale switches dialogue and menus to a locale. Text the locale doesn't have is shown in the default language.
+func (g *Game) SetLocale(name string) error {
+	locale, ok := g.Locales[name]
+	if !ok {
+		return fmt.Errorf("unknown locale %q, expected one of %s", name, strings.Join(g.LocaleNames(), ", "))
+	}
+	fallback := g.Locales[DefaultLocale]
+	for k, graph := range g.DialogueGraphs {
+		for id, node := range graph.Nodes {
+			text := fallback.Dialogue[k][id]
+			localized := locale.Dialogue[k][id]
+			node.Phrase = text.Phrase
+			if localized.Phrase != "" {
+				node.Phrase = localized.Phrase
+			}
+			node.RuneNum = Min(node.RuneNum, RuneCount(node.Phrase))
+			for i := range node.Options {
+				if i < len(text.Options) {
+					node.Options[i].Text = text.Options[i]
+				}
+				if i < len(localized.Options) && localized.Options[i] != "" {
+					node.Options[i].Text = localized.Options[i]
+				}
+			}
+		}
+	}
+	g.Settings.Locale = name
+	return nil
+}
+
+// Text returns menu text in the current locale, or as it is if the locale doesn't have it
+func (g *Game) Text(s string) string {
+	if locale, ok := g.Locales[g.Settings.Locale]; ok {
+		if text, ok := locale.UI[s]; ok {
+			return text
+		}
+	}
+	return s
+}
+
+// MenuText returns the title and options of a menu as they are drawn in the current locale.
+// Menus built in the current locale, like the ones that show values, are drawn as they are.
+func (g *Game) MenuText(m *Menu) (string, []string) {
+	if m.Localized {
+		return m.Title, m.Options
+	}
+	options := make([]string, len(m.Options))
+	for i, option := range m.Options {
+		options[i] = g.Text(option)
+	}
+	return g.Text(m.Title), options
+}
+
+// WrapText splits text into lines no wider than width, measuring text with measure. Lines break at spaces, and
+// between the characters of languages written without spaces like Japanese and Chinese. A word wider than a line is
+// broken between any of its characters.
+func WrapText(text string, width int, measure func(string) int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		for i, segment := range breakSegments(word) {
+			if line != "" {
+				next := line + segment
+				if i == 0 {
+					next = line + " " + segment
+				}
+				if measure(next) <= width {
+					line = next
+					continue
+				}
+				lines = append(lines, line)
+			}
+			line = segment
+
+			// A segment wider than a line is broken between its characters
+			for measure(line) > width && len([]rune(line)) > 1 {
+				runes := []rune(line)
+				n := len(runes) - 1
+				for n > 1 && measure(string(runes[:n])) > width {
+					n--
+				}
+				lines = append(lines, string(runes[:n]))
+				line = string(runes[n:])
+			}
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// breakSegments splits a word into the parts a line can break between. Each character of a language written without
+// spaces is its own part, keeping punctuation that can't start a line with the character before it.
+func breakSegments(word string) []string {
+	var segments []string
+	var current []rune
+	for _, r := range word {
+		switch {
+		case len(current) > 0 && isNoBreakBefore(r):
+			current = append(current, r)
+		case isBreakable(r):
+			if len(current) > 0 {
+				segments = append(segments, string(current))
+			}
+			current = []rune{r}
+		default:
+			if len(current) > 0 && isBreakable(current[len(current)-1]) {
+				segments = append(segments, string(current))
+				current = nil
+			}
+			current = append(current, r)
+		}
+	}
+	if len(current) > 0 {
+		segments = append(segments, string(current))
+	}
+	return segments
+}
+
+// isBreakable returns true for characters of languages written without spaces, which lines can break around
+func isBreakable(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana) || r == 'ー' || r == '々'
+}
+
+// isNoBreakBefore returns true for punctuation that can't start a line, like closing brackets and full stops
+func isNoBreakBefore(r rune) bool {
+	return strings.ContainsRune("、。，．・：；？！）」』】〉》〕ーぁぃぅぇぉっゃゅょァィゥェォッャュョ…,.!?:;)]}", r)
+}
@@ -0,0 +1,148 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	// Every character is 1 wide, so the width is a number of characters
+	measure := func(s string) int { return len([]rune(s)) }
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"Are you having fun?", 10, []string{"Are you", "having", "fun?"}},
+		{"  spaces   between ", 20, []string{"spaces between"}},
+		{"楽しんでいますか？はい。", 5, []string{"楽しんでい", "ますか？は", "い。"}},
+		{"レベルアップ", 4, []string{"レベル", "アップ"}},
+		{"Go言語で遊ぶ", 4, []string{"Go言語", "で遊ぶ"}},
+		{"supercalifragilistic word", 8, []string{"supercal", "ifragili", "stic", "word"}},
+		{"", 10, nil},
+	}
+	for _, test := range tests {
+		if got := WrapText(test.text, test.width, measure); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("WrapText(%q, %d) = %q, want %q", test.text, test.width, got, test.want)
+		}
+	}
+}
+
+func TestLocaleFallsBackToDefault(t *testing.T) {
+	graphs, err := LoadDialogueGraphs("../dialogue/dialogue.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGame(nil, graphs, &ScriptedInput{})
+	if g.Locales, err = LoadLocales("../dialogue", graphs); err != nil {
+		t.Fatal(err)
+	}
+	// Leave out text, which should be shown in the default language
+	delete(g.Locales["fr"].Dialogue["elder"], "elder_intro_1")
+	g.Locales["fr"].Dialogue["elder"]["elder_fun"] = DialogueText{Options: []string{"", "non"}}
+
+	if err := g.SetLocale("fr"); err != nil {
+		t.Fatal(err)
+	}
+	elder := graphs["elder"]
+	if phrase := elder.Nodes["elder_intro_0"].Phrase; phrase != g.Locales["fr"].Dialogue["elder"]["elder_intro_0"].Phrase {
+		t.Errorf("expected the phrase in french, got %q", phrase)
+	}
+	if phrase := elder.Nodes["elder_intro_1"].Phrase; phrase != g.Locales[DefaultLocale].Dialogue["elder"]["elder_intro_1"].Phrase {
+		t.Errorf("expected a missing phrase in the default language, got %q", phrase)
+	}
+	if options := elder.Nodes["elder_fun"].Options; options[0].Text != "yes" || options[1].Text != "non" {
+		t.Errorf("expected a missing option in the default language, got %+v", options)
+	}
+	if g.Text(BackOption) != "Retour" || g.Text("Unknown") != "Unknown" {
+		t.Errorf("expected menu text in french, or as it is if missing, got %q and %q", g.Text(BackOption), g.Text("Unknown"))
+	}
+
+	if err := g.SetLocale(DefaultLocale); err != nil {
+		t.Fatal(err)
+	}
+	if phrase := elder.Nodes["elder_intro_question"].Phrase; phrase != "Are you having fun?" {
+		t.Errorf("expected the phrase back in the default language, got %q", phrase)
+	}
+	if err := g.SetLocale("xx"); err == nil {
+		t.Error("expected an error for an unknown locale")
+	}
+}
+
+func TestSettingsChangeLocale(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json", Hold(1, Confirm), Hold(1))
+	settings := NewSettingsScene(g)
+	settings.Menu.Selected = int(ChannelCount) + 1
+	g.PushScene(settings)
+
+	step(t, g, 2)
+	if g.Settings.Locale != "fr" {
+		t.Fatalf("expected the language option to switch to fr, got %q", g.Settings.Locale)
+	}
+	if options := settings.Menu.Options; options[len(options)-2] != "Langue: fr" || options[len(options)-1] != "Retour" {
+		t.Errorf("expected the settings menu in french, got %v", options)
+	}
+}
+
+func TestMenusAreLocalizedOnce(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json")
+	if err := g.SetLocale("fr"); err != nil {
+		t.Fatal(err)
+	}
+	g.Player.Items["rupee"] = 2
+
+	title, options := g.MenuText(NewPauseScene().Menu)
+	if title != "Pause" || options[0] != "Reprendre" || options[len(options)-1] != "Quitter" {
+		t.Errorf("expected the pause menu to be drawn in french, got %q %v", title, options)
+	}
+	// Text that was built in french isn't looked up again, which would change it if it were also a key
+	g.Locales["fr"].UI["Inventaire"] = "twice"
+	g.Locales["fr"].UI["rubis x2"] = "twice"
+	title, options = g.MenuText(NewInventoryScene(g.Player.Items, g.Text).Menu)
+	if title != "Inventaire" || len(options) != 1 || options[0] != "rubis x2" {
+		t.Errorf("expected the inventory to be drawn as it was built in french, got %q %v", title, options)
+	}
+	settings := NewSettingsScene(g).Menu
+	if title, options := g.MenuText(settings); title != "Options" || options[0] != "Plein écran: Non" || options[len(options)-1] != "Retour" {
+		t.Errorf("expected the settings menu to be drawn as it was built in french, got %q %v", title, options)
+	}
+}
+
+func TestLoadLocalesErrors(t *testing.T) {
+	graphs, err := LoadDialogueGraphs("../dialogue/dialogue.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"unknown dialogue": `{"wizard": {}}`,
+		"unknown node":     `{"elder": {"elder_typo": {"phrase": "salut"}}}`,
+		"extra option":     `{"elder": {"elder_fun": {"options": ["oui", "non", "peut-être"]}}}`,
+		"bad json":         `{"elder": [}`,
+	}
+	for name, dialogue := range tests {
+		dir := t.TempDir()
+		if err := os.Mkdir(filepath.Join(dir, "fr"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "fr", "dialogue.json"), []byte(dialogue), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadLocales(dir, graphs); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	// The default locale comes from the dialogue graphs, and a directory can't replace it
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, DefaultLocale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, DefaultLocale, "dialogue.json"), []byte(`{"elder": {"elder_fun": {"options": ["yes"]}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLocales(dir, graphs); err == nil {
+		t.Error("expected an error for a directory of the default locale")
+	}
+}
@@ -0,0 +1,144 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// HealthItem is the item that heals the player instead of being counted
+const HealthItem = "health"
+
+// LootTable is what an enemy type can drop when it dies
+type LootTable struct {
+	Rolls int        // How many drops are rolled for each death
+	Drops []LootDrop // The drops to roll from, picked by weight
+}
+
+// LootDrop is one possible result of a loot roll
+type LootDrop struct {
+	Item   string // The item the pickup gives, or empty if nothing drops
+	Amount int    // How many of the item the pickup gives
+	Sprite Sprite // The sprite of the pickup
+	Weight int    // How likely the drop is compared to the others in its table
+}
+
+// LootTableJSON represents a loot table in the loot json file
+type LootTableJSON struct {
+	Rolls int            `json:"rolls"`
+	Drops []LootDropJSON `json:"drops"`
+}
+
+// LootDropJSON represents a drop in the loot json file. A drop without an item is a roll that drops nothing.
+type LootDropJSON struct {
+	Item   string `json:"item"`
+	Amount int    `json:"amount"`
+	Sprite string `json:"sprite"`
+	Weight int    `json:"weight"`
+}
+
+// LoadLootTables reads a loot json file of loot tables keyed by enemy type and resolves their sprites
+func LoadLootTables(path string, sprites map[string]Sprite) (map[string]LootTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonTables map[string]LootTableJSON
+	if err := json.Unmarshal(data, &jsonTables); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+
+	tables := map[string]LootTable{}
+	for k, v := range jsonTables {
+		if v.Rolls < 0 {
+			return nil, fmt.Errorf("%s: %s.rolls: must not be negative, got %d", path, k, v.Rolls)
+		}
+		table := LootTable{Rolls: v.Rolls}
+		for i, d := range v.Drops {
+			if d.Weight <= 0 {
+				return nil, fmt.Errorf("%s: %s.drops[%d].weight: must be positive, got %d", path, k, i, d.Weight)
+			}
+			drop := LootDrop{Item: d.Item, Amount: d.Amount, Weight: d.Weight}
+			if d.Item != "" {
+				if d.Amount <= 0 {
+					return nil, fmt.Errorf("%s: %s.drops[%d].amount: must be positive, got %d", path, k, i, d.Amount)
+				}
+				sprite, ok := sprites[d.Sprite]
+				if !ok {
+					return nil, fmt.Errorf("%s: %s.drops[%d].sprite: unknown sprite %q", path, k, i, d.Sprite)
+				}
+				drop.Sprite = sprite
+			}
+			table.Drops = append(table.Drops, drop)
+		}
+		tables[k] = table
+	}
+	return tables, nil
+}
+
+// Roll picks a drop from the table by weight
+func (t LootTable) Roll(g *Game) LootDrop {
+	var total int
+	for _, d := range t.Drops {
+		total += d.Weight
+	}
+	if total == 0 {
+		return LootDrop{}
+	}
+	n := g.Roll(total)
+	for _, d := range t.Drops {
+		if n < d.Weight {
+			return d
+		}
+		n -= d.Weight
+	}
+	return LootDrop{}
+}
+
+// Roll returns a random number from 0 up to but not including n.
+// Each roll is decided by the seed of the game and how many rolls came before it, so rolls are the same in a replay and after loading a save.
+func (g *Game) Roll(n int) int {
+	g.Rolls++
+	// splitmix64
+	x := uint64(g.Seed) + uint64(g.Rolls)*0x9e3779b97f4a7c15
+	x = (x ^ x>>30) * 0xbf58476d1ce4e5b9
+	x = (x ^ x>>27) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return int(x % uint64(n))
+}
+
+// DropLoot rolls the loot table of an enemy's type and drops the pickups around where it died
+func DropLoot(g *Game, e *Enemy) {
+	table, ok := g.LootTables[e.Type]
+	if !ok {
+		return
+	}
+	for i := 0; i < table.Rolls; i++ {
+		drop := table.Roll(g)
+		if drop.Item == "" {
+			continue
+		}
+		// Spread several drops out in a row so they don't cover each other
+		g.Pickups = append(g.Pickups, Pickup{
+			X:      e.X + float64((i-(table.Rolls-1)/2)*10),
+			Y:      e.Y,
+			Sprite: drop.Sprite,
+			Item:   drop.Item,
+			Amount: drop.Amount,
+		})
+	}
+}
+
+// Collect gives the player the item of a pickup and marks it to be removed
+func Collect(g *Game, p *Pickup) {
+	if p.Item == HealthItem {
+		g.Player.Health = Min(g.Player.Health+p.Amount, g.Player.MaxHealth)
+	} else {
+		if g.Player.Items == nil {
+			g.Player.Items = map[string]int{}
+		}
+		g.Player.Items[p.Item] += p.Amount
+	}
+	p.Collected = true
+}
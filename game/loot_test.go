@@ -0,0 +1,70 @@
+package game
+
+import (
+	"testing"
+)
+
+func TestLootRollsFollowWeightsAndSeed(t *testing.T) {
+	table := LootTable{Rolls: 1, Drops: []LootDrop{{Weight: 1}, {Item: "rupee", Amount: 1, Weight: 3}}}
+	g := &Game{Seed: 7}
+	var rupees int
+	var rolled []string
+	for i := 0; i < 4000; i++ {
+		drop := table.Roll(g)
+		if drop.Item == "rupee" {
+			rupees++
+		}
+		rolled = append(rolled, drop.Item)
+	}
+	// A weight of 3 out of 4 should come up about 3000 times
+	if rupees < 2850 || rupees > 3150 {
+		t.Errorf("expected about 3000 rupees from 4000 rolls, got %d", rupees)
+	}
+
+	// The same seed rolls the same drops, even when continued from a saved roll count
+	g = &Game{Seed: 7, Rolls: 2000}
+	for i := 2000; i < len(rolled); i++ {
+		if drop := table.Roll(g); drop.Item != rolled[i] {
+			t.Fatalf("expected roll %d to be %q again, got %q", i, rolled[i], drop.Item)
+		}
+	}
+}
+
+func TestLoadLootTables(t *testing.T) {
+	sprites := map[string]Sprite{"heart": {Key: "heart"}, "rupee": {Key: "rupee"}}
+	tables, err := LoadLootTables("../loot/loot.json", sprites)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tables["skeletonWizard"]; !ok {
+		t.Errorf("expected a loot table for the skeleton wizard, got %v", tables)
+	}
+
+	if _, err := LoadLootTables("../loot/loot.json", map[string]Sprite{}); err == nil {
+		t.Error("expected an error for drops with unknown sprites")
+	}
+}
+
+func TestCollectPickups(t *testing.T) {
+	box := Sprite{FrameWidth: 8, FrameHeight: 8, FrameLen: 1}
+	g := &Game{
+		Player: Player{X: 100, Y: 100, Sprite: Sprite{FrameWidth: 16, FrameHeight: 16}, Health: 95, MaxHealth: 100},
+		Pickups: []Pickup{
+			{X: 100, Y: 100, Sprite: box, Item: HealthItem, Amount: 10},
+			{X: 104, Y: 100, Sprite: box, Item: "rupee", Amount: 5},
+			{X: 200, Y: 100, Sprite: box, Item: "rupee", Amount: 1},
+		},
+	}
+	g.IndexColliders()
+	UpdatePickups(g)
+	UpdateDespawns(g)
+	if g.Player.Health != 100 {
+		t.Errorf("expected the heart to heal up to the max health of 100, got %d", g.Player.Health)
+	}
+	if g.Player.Items["rupee"] != 5 {
+		t.Errorf("expected to collect 5 rupees, got %d", g.Player.Items["rupee"])
+	}
+	if len(g.Pickups) != 1 || g.Pickups[0].X != 200 {
+		t.Errorf("expected only the far away rupee to be left, got %+v", g.Pickups)
+	}
+}
@@ -0,0 +1,21 @@
+package game
+
+// Menu is a list of options the player picks one of with the menu actions and confirms
+type Menu struct {
+	Title     string   // The heading drawn above the options
+	Options   []string // The options to pick from, in the order they are drawn
+	Selected  int      // The index of the selected option
+	Localized bool     // Whether the title and options were built in the current locale, rather than being text to look up
+}
+
+// Update moves the selection with the menu actions, and returns the selected option if it was confirmed or "" if not
+func (m *Menu) Update(in Input) string {
+	if in.IsJustReleased(MenuUp) || in.IsJustReleased(MenuLeft) {
+		m.Selected = Max(m.Selected-1, 0)
+	} else if in.IsJustReleased(MenuDown) || in.IsJustReleased(MenuRight) {
+		m.Selected = Max(Min(m.Selected+1, len(m.Options)-1), 0)
+	} else if in.IsJustReleased(Confirm) && len(m.Options) > 0 {
+		return m.Options[m.Selected]
+	}
+	return ""
+}
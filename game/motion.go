@@ -0,0 +1,64 @@
+package game
+
+import (
+	"math"
+)
+
+// Motion is the velocity of an entity and how quickly it changes. Positions are float64 so speeds can be fractions of a pixel.
+type Motion struct {
+	VX       float64 // The X velocity in pixels per tick
+	VY       float64 // The Y velocity in pixels per tick
+	Speed    float64 // The top speed the entity moves at on its own, in pixels per tick
+	Accel    float64 // How much the velocity changes per tick while the entity moves on its own, or 0 to reach top speed at once
+	Friction float64 // How much the velocity slows per tick while the entity doesn't move on its own, or 0 to stop at once
+}
+
+// Steer changes the velocity towards moving at top speed in a direction, or towards stopping if the direction is 0.
+// Diagonal directions are normalized so they aren't faster than straight ones.
+func (m *Motion) Steer(dx, dy float64) {
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		m.VX = Approach(m.VX, 0, m.Friction)
+		m.VY = Approach(m.VY, 0, m.Friction)
+		return
+	}
+	m.VX = Approach(m.VX, dx/length*m.Speed, m.Accel)
+	m.VY = Approach(m.VY, dy/length*m.Speed, m.Accel)
+}
+
+// Push adds an impulse to the velocity, like knockback
+func (m *Motion) Push(vx, vy float64) {
+	m.VX += vx
+	m.VY += vy
+}
+
+// Approach moves v towards target by at most step. A step of 0 or less reaches the target at once.
+func Approach(v, target, step float64) float64 {
+	if step <= 0 {
+		return target
+	}
+	if v < target {
+		return math.Min(v+step, target)
+	}
+	return math.Max(v-step, target)
+}
+
+// Pixel rounds a position to the whole pixel it is drawn and collides at. Halves round up, so moving by a whole number of pixels
+// always moves the rounded position by the same amount.
+func Pixel(v float64) int {
+	return int(math.Floor(v + 0.5))
+}
+
+// Vector returns the unit vector pointing in a direction
+func (d Direction) Vector() (float64, float64) {
+	switch d {
+	case North:
+		return 0, -1
+	case West:
+		return -1, 0
+	case East:
+		return 1, 0
+	default:
+		return 0, 1
+	}
+}
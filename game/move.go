@@ -0,0 +1,135 @@
+package game
+
+import (
+	"image"
+)
+
+// CollisionLayer is a set of kinds of collider. Every collider is on one layer, and masks combine layers with |.
+type CollisionLayer int
+
+const (
+	TileLayer CollisionLayer = 1 << iota
+	DoodadLayer
+	CharacterLayer
+	EnemyLayer
+	PlayerLayer
+	EnemyProjectileLayer
+	PlayerProjectileLayer
+)
+
+// CollisionRule is how bodies on a layer collide when they move
+type CollisionRule struct {
+	Mask  CollisionLayer // The layers that block the body
+	Slide bool           // Whether a diagonal move keeps going along one axis when the other is blocked
+	Nudge int            // How many pixels a body can clip the corner of a collider and still be nudged sideways around it, or 0
+}
+
+// DefaultCollisionRules returns how bodies on each layer collide.
+// Projectiles are never indexed, so nothing is blocked by them, and enemy fireballs fly over tiles.
+func DefaultCollisionRules() map[CollisionLayer]CollisionRule {
+	scenery := TileLayer | DoodadLayer | CharacterLayer
+	return map[CollisionLayer]CollisionRule{
+		PlayerLayer:           {Mask: scenery | EnemyLayer, Slide: true, Nudge: 4},
+		EnemyLayer:            {Mask: scenery | EnemyLayer | PlayerLayer, Slide: true, Nudge: 4},
+		CharacterLayer:        {Mask: scenery | EnemyLayer | PlayerLayer, Slide: true},
+		EnemyProjectileLayer:  {Mask: DoodadLayer | CharacterLayer | PlayerLayer},
+		PlayerProjectileLayer: {Mask: DoodadLayer | EnemyLayer},
+	}
+}
+
+// Body is a collider that can be moved with Move
+type Body interface {
+	Collider
+	Position() (float64, float64) // The position of the body
+	Translate(dx, dy float64)     // Moves the body without checking for collisions
+}
+
+// Move moves a body by up to dx and dy, along X and then along Y, following the collision rule of its layer.
+// Collisions are checked one whole pixel at a time, and the fraction of a pixel left over is kept in the position.
+// A step that would overlap a collider in the mask is blocked. If the body only clips the corner of what blocked it by up to
+// the rule's nudge, it is nudged one pixel sideways around the corner instead. Otherwise the rest of that axis is cancelled,
+// and the rest of the move too if the rule doesn't slide.
+// It returns every collider the body ran into, including corners it was nudged around.
+func Move(g *Game, b Body, dx, dy float64) []Collider {
+	rule := g.CollisionRules[b.CollisionLayer()]
+	var hits []Collider
+	for _, step := range [2][2]float64{{dx, 0}, {0, dy}} {
+		fromX, fromY := b.Position()
+		x := Pixel(fromX+step[0]) - Pixel(fromX)
+		y := Pixel(fromY+step[1]) - Pixel(fromY)
+		whole := true
+		for n := Abs(x + y); n > 0; n-- {
+			blocked := Blocking(g, b, Sign(x), Sign(y), rule.Mask)
+			if len(blocked) == 0 {
+				b.Translate(float64(Sign(x)), float64(Sign(y)))
+				g.Colliders.Update(b)
+				continue
+			}
+			whole = false
+			hits = append(hits, blocked...)
+			if Nudge(g, b, Sign(x), Sign(y), blocked, rule) {
+				continue
+			}
+			if !rule.Slide {
+				return hits
+			}
+			break
+		}
+		// Every whole pixel was free, so move the rest of the way within the last pixel
+		if whole {
+			toX, toY := b.Position()
+			b.Translate(fromX+step[0]-toX, fromY+step[1]-toY)
+		}
+	}
+	return hits
+}
+
+// Nudge moves a body one pixel sideways around the corner of the colliders blocking a step of x and y,
+// if the body overlaps them by no more than the rule's nudge. It returns false if the body wasn't nudged.
+func Nudge(g *Game, b Body, x, y int, blocked []Collider, rule CollisionRule) bool {
+	if rule.Nudge <= 0 {
+		return false
+	}
+	hitbox := b.Hitbox(x, y)
+	var r image.Rectangle
+	for _, c := range blocked {
+		r = r.Union(c.Hitbox(0, 0))
+	}
+
+	// Compare the extents across the direction of the step
+	min, max, blockedMin, blockedMax := hitbox.Min.Y, hitbox.Max.Y, r.Min.Y, r.Max.Y
+	if y != 0 {
+		min, max, blockedMin, blockedMax = hitbox.Min.X, hitbox.Max.X, r.Min.X, r.Max.X
+	}
+	var side int
+	if blockedMin <= min && blockedMax-min <= rule.Nudge {
+		// Only the top or left edge of the body is caught, so nudge it down or right
+		side = 1
+	} else if blockedMax >= max && max-blockedMin <= rule.Nudge {
+		side = -1
+	} else {
+		return false
+	}
+
+	nx, ny := 0, side
+	if y != 0 {
+		nx, ny = side, 0
+	}
+	if len(Blocking(g, b, nx, ny, rule.Mask)) > 0 {
+		return false
+	}
+	b.Translate(float64(nx), float64(ny))
+	g.Colliders.Update(b)
+	return true
+}
+
+// Blocking returns the colliders on the layers in mask that a collider would overlap if it moved by x and y
+func Blocking(g *Game, c Collider, x, y int, mask CollisionLayer) []Collider {
+	var blocked []Collider
+	for _, v := range g.Colliders.Query(c.Hitbox(x, y)) {
+		if v != c && v.CollisionLayer()&mask != 0 {
+			blocked = append(blocked, v)
+		}
+	}
+	return blocked
+}
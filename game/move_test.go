@@ -0,0 +1,196 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+// newMoveGame creates a game with the player far away, a stump, a tree, an elder and a wizard in a row 16 pixels apart
+func newMoveGame() *Game {
+	box := Sprite{FrameWidth: 16, FrameHeight: 16, FrameLen: 1}
+	g := &Game{
+		Player:         Player{X: 1000, Y: 1000, Sprite: box},
+		Tiles:          []Tile{{X: 0, Y: 100, Sprite: box, Collider: true}},
+		Doodads:        []Doodad{{X: 32, Y: 100, Sprite: box}},
+		Characters:     []Character{{X: 64, Y: 100, Sprite: box}},
+		Enemies:        []Enemy{{X: 96, Y: 100, Sprite: box}},
+		CollisionRules: DefaultCollisionRules(),
+	}
+	g.IndexColliders()
+	return g
+}
+
+func TestMoveStopsAtFirstBlockingCollider(t *testing.T) {
+	g := newMoveGame()
+	e := &g.Enemies[0]
+	hits := Move(g, e, -50, 0)
+	if len(hits) != 1 || hits[0] != Collider(&g.Characters[0]) {
+		t.Fatalf("expected the enemy to hit the character, got %v", hits)
+	}
+	if e.X != 80 {
+		t.Errorf("expected the enemy to stop against the character at x 80, got %v", e.X)
+	}
+	if got := g.Colliders.Query(e.Hitbox(0, 0)); len(got) != 1 || got[0] != Collider(e) {
+		t.Errorf("expected the spatial hash to follow the enemy, got %v", got)
+	}
+}
+
+func TestMoveReturnsNothingWhenUnblocked(t *testing.T) {
+	g := newMoveGame()
+	if hits := Move(g, &g.Player, -5, 7); len(hits) != 0 {
+		t.Errorf("expected no hits, got %v", hits)
+	}
+	if g.Player.X != 995 || g.Player.Y != 1007 {
+		t.Errorf("expected the player at (995,1007), got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+}
+
+func TestMoveUsesCollisionMask(t *testing.T) {
+	g := newMoveGame()
+	rule := g.CollisionRules[EnemyLayer]
+	rule.Mask &^= CharacterLayer
+	g.CollisionRules[EnemyLayer] = rule
+	e := &g.Enemies[0]
+	hits := Move(g, e, -50, 0)
+	if len(hits) != 1 || hits[0] != Collider(&g.Doodads[0]) {
+		t.Fatalf("expected the enemy to walk through the character and hit the doodad, got %v", hits)
+	}
+	if e.X != 48 {
+		t.Errorf("expected the enemy to stop against the doodad at x 48, got %v", e.X)
+	}
+}
+
+func TestEnemyProjectilesFlyOverTiles(t *testing.T) {
+	g := newMoveGame()
+	p := Projectile{X: -40, Y: 100, Sprite: Sprite{FrameWidth: 16, FrameHeight: 7}, Motion: Motion{Speed: 3}, Dir: East, IsEnemy: true}
+	hits := Move(g, &p, 60, 0)
+	if len(hits) != 1 || hits[0] != Collider(&g.Doodads[0]) {
+		t.Fatalf("expected the fireball to pass the stump and hit the doodad, got %v", hits)
+	}
+	if p.X != 16 {
+		t.Errorf("expected the fireball to stop against the doodad at x 16, got %v", p.X)
+	}
+}
+
+func TestEnemyWalkingIntoPlayerTouchesIt(t *testing.T) {
+	g := newMoveGame()
+	g.Player.X, g.Player.Y = 120, 100
+	g.Colliders.Update(&g.Player)
+	MoveEnemy(g, &g.Enemies[0], 10, 0)
+	if g.Enemies[0].X != 104 {
+		t.Errorf("expected the player to stop the enemy at x 104, got %v", g.Enemies[0].X)
+	}
+	if g.EnemyCollision != &g.Enemies[0] {
+		t.Errorf("expected the enemy to touch the player, got %v", g.EnemyCollision)
+	}
+}
+
+// newNudgeGame creates a game with only the player and a doodad, whose hitbox is from (-8,92) to (8,100)
+func newNudgeGame(x, y float64) *Game {
+	box := Sprite{FrameWidth: 16, FrameHeight: 16, FrameLen: 1}
+	g := &Game{
+		Player:         Player{X: x, Y: y, Sprite: box},
+		Doodads:        []Doodad{{X: 0, Y: 100, Sprite: box}},
+		CollisionRules: DefaultCollisionRules(),
+	}
+	g.IndexColliders()
+	return g
+}
+
+func TestNudgeAroundCorner(t *testing.T) {
+	// The player hitbox is from y 96 to 104, so it only clips the bottom corner of the doodad by 4 pixels
+	g := newNudgeGame(20, 104)
+	hits := Move(g, &g.Player, -10, 0)
+	if len(hits) == 0 {
+		t.Fatal("expected the player to run into the corner of the doodad")
+	}
+	// 4 steps to reach the doodad, 4 steps nudging down past its corner, then 2 more steps left
+	if g.Player.X != 14 || g.Player.Y != 108 {
+		t.Errorf("expected the player to be nudged around the corner to (14,108), got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+	if g.Player.Hitbox(0, 0).Overlaps(g.Doodads[0].Hitbox(0, 0)) {
+		t.Error("expected the nudge to never overlap the doodad")
+	}
+}
+
+func TestNoNudgeWhenHeadOn(t *testing.T) {
+	g := newNudgeGame(20, 103)
+	Move(g, &g.Player, -10, 0)
+	if g.Player.X != 16 || g.Player.Y != 103 {
+		t.Errorf("expected the player to stop against the doodad at (16,103), got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+
+	g = newNudgeGame(20, 104)
+	rule := g.CollisionRules[PlayerLayer]
+	rule.Nudge = 0
+	g.CollisionRules[PlayerLayer] = rule
+	Move(g, &g.Player, -10, 0)
+	if g.Player.X != 16 || g.Player.Y != 104 {
+		t.Errorf("expected the player not to be nudged with nudging turned off, got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+}
+
+func TestSlide(t *testing.T) {
+	g := newNudgeGame(16, 100)
+	Move(g, &g.Player, -5, -5)
+	if g.Player.X != 16 || g.Player.Y != 95 {
+		t.Errorf("expected the player to slide up along the doodad to (16,95), got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+
+	g = newNudgeGame(16, 100)
+	rule := g.CollisionRules[PlayerLayer]
+	rule.Slide = false
+	g.CollisionRules[PlayerLayer] = rule
+	Move(g, &g.Player, -5, -5)
+	if g.Player.X != 16 || g.Player.Y != 100 {
+		t.Errorf("expected the player to stop without sliding at (16,100), got (%v,%v)", g.Player.X, g.Player.Y)
+	}
+}
+
+func TestMoveKeepsFractionOfPixel(t *testing.T) {
+	g := newMoveGame()
+	for i := 0; i < 4; i++ {
+		Move(g, &g.Player, 1.5, 0)
+	}
+	if g.Player.X != 1006 {
+		t.Errorf("expected 4 moves of 1.5 pixels to reach x 1006, got %v", g.Player.X)
+	}
+
+	// Moves of less than a pixel add up until the rounded position changes
+	g.Player.X = 1000
+	for i := 0; i < 3; i++ {
+		Move(g, &g.Player, 0.125, 0)
+	}
+	if got := g.Player.Hitbox(0, 0).Min.X; got != 992 {
+		t.Errorf("expected the hitbox to stay put after moving 0.375 pixels, got min x %d", got)
+	}
+	Move(g, &g.Player, 0.125, 0)
+	if got := g.Player.Hitbox(0, 0).Min.X; got != 993 {
+		t.Errorf("expected the hitbox to move one pixel once the position rounds up, got min x %d", got)
+	}
+}
+
+func TestSteerNormalizesDiagonals(t *testing.T) {
+	m := Motion{Speed: 2}
+	m.Steer(1, -1)
+	if speed := math.Hypot(m.VX, m.VY); math.Abs(speed-2) > 1e-9 {
+		t.Errorf("expected a diagonal speed of 2, got %v", speed)
+	}
+
+	m = Motion{Speed: 2, Accel: 0.5, Friction: 0.25}
+	m.Steer(1, 0)
+	if m.VX != 0.5 {
+		t.Errorf("expected to accelerate to 0.5, got %v", m.VX)
+	}
+	for i := 0; i < 10; i++ {
+		m.Steer(1, 0)
+	}
+	if m.VX != 2 {
+		t.Errorf("expected to reach the top speed of 2, got %v", m.VX)
+	}
+	m.Push(-6, 0)
+	m.Steer(0, 0)
+	if m.VX != -3.75 {
+		t.Errorf("expected knockback to slow by the friction to -3.75, got %v", m.VX)
+	}
+}
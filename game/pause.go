@@ -0,0 +1,131 @@
+package game
+
+import (
+	"fmt"
+	"log"
+)
+
+const (
+	ResumeOption   = "Resume"
+	SettingsOption = "Settings"
+	SaveOption     = "Save"
+	BackOption     = "Back"
+)
+
+// Settings are the options the player picks in the settings menu. The main package applies them to the window.
+type Settings struct {
+	Fullscreen bool
+	Volumes    [ChannelCount]int // The volume of each channel in percent
+	Locale     string            // The language of dialogue and menus, like en or fr
+}
+
+// DefaultSettings returns the settings of a new game
+func DefaultSettings() Settings {
+	return Settings{Volumes: [ChannelCount]int{100, 100, 100}, Locale: DefaultLocale}
+}
+
+// PauseScene is the menu shown over the frozen world while the game is paused
+type PauseScene struct {
+	Menu *Menu
+}
+
+// NewPauseScene creates the pause menu
+func NewPauseScene() *PauseScene {
+	return &PauseScene{Menu: &Menu{Title: "Paused", Options: []string{ResumeOption, SettingsOption, SaveOption, QuitOption}}}
+}
+
+// Update lets the player pick an option of the pause menu, or resume with the pause action. It returns ErrQuit if they quit.
+func (s *PauseScene) Update(g *Game) error {
+	if g.Input.IsJustReleased(Pause) {
+		g.PopScene()
+		return nil
+	}
+	switch s.Menu.Update(g.Input) {
+	case ResumeOption:
+		g.PopScene()
+	case SettingsOption:
+		g.PushScene(NewSettingsScene(g))
+	case SaveOption:
+		s.Menu.Title = "Saved"
+		if err := g.SaveSlot(QuickSaveSlot); err != nil {
+			log.Println(err)
+			s.Menu.Title = "Save failed"
+		}
+	case QuitOption:
+		return ErrQuit
+	}
+	return nil
+}
+
+// Overlay returns true, as the world is drawn dimmed under the pause menu
+func (s *PauseScene) Overlay() bool {
+	return true
+}
+
+// SettingsScene is the menu of settings opened from the pause menu
+type SettingsScene struct {
+	Menu *Menu
+}
+
+// NewSettingsScene creates the settings menu showing the current settings of a game
+func NewSettingsScene(g *Game) *SettingsScene {
+	return &SettingsScene{Menu: &Menu{Title: g.Text(SettingsOption), Options: SettingsOptions(g), Localized: true}}
+}
+
+// SettingsOptions returns the options of the settings menu in the current locale, which show the current value of each setting
+func SettingsOptions(g *Game) []string {
+	fullscreen := "Off"
+	if g.Settings.Fullscreen {
+		fullscreen = "On"
+	}
+	options := []string{g.Text("Fullscreen") + ": " + g.Text(fullscreen)}
+	for c, volume := range g.Settings.Volumes {
+		options = append(options, fmt.Sprintf("%s: %d%%", g.Text(channelLabels[c]), volume))
+	}
+	return append(options, g.Text("Language")+": "+g.Settings.Locale, g.Text(BackOption))
+}
+
+// Update changes the selected setting when it is confirmed, and goes back to the pause menu with the back option or
+// the pause action
+func (s *SettingsScene) Update(g *Game) error {
+	if g.Input.IsJustReleased(Pause) {
+		g.PopScene()
+		return nil
+	}
+	// The options are in the current locale, so they are told apart by their position
+	if s.Menu.Update(g.Input) == "" {
+		return nil
+	}
+	switch selected := s.Menu.Selected; {
+	case selected == 0:
+		g.Settings.Fullscreen = !g.Settings.Fullscreen
+	case selected <= int(ChannelCount):
+		// The volume options lower the volume of their channel a step at a time, then go back up to full volume
+		volume := &g.Settings.Volumes[selected-1]
+		if *volume -= VolumeStep; *volume < 0 {
+			*volume = 100
+		}
+	case selected == int(ChannelCount)+1:
+		// The language option moves on to the next locale, then back to the first
+		names := g.LocaleNames()
+		next := names[0]
+		for i, name := range names[:len(names)-1] {
+			if name == g.Settings.Locale {
+				next = names[i+1]
+			}
+		}
+		if err := g.SetLocale(next); err != nil {
+			return err
+		}
+	default:
+		g.PopScene()
+		return nil
+	}
+	s.Menu.Title, s.Menu.Options = g.Text(SettingsOption), SettingsOptions(g)
+	return nil
+}
+
+// Overlay returns true, as the world is drawn dimmed under the settings menu
+func (s *SettingsScene) Overlay() bool {
+	return true
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPauseFreezesWorld(t *testing.T) {
+	g := newTestGame(t, "testdata/wizard.json", Hold(70, MoveLeft), Hold(1, Pause), Hold(60, MoveLeft),
+		Hold(1, MenuDown), Hold(1), Hold(1, Confirm), Hold(1), Hold(1, Confirm), Hold(1), Hold(1, Pause), Hold(1), Hold(1, Pause), Hold(1))
+
+	step(t, g, 72)
+	if _, ok := g.Scene().(*PauseScene); !ok {
+		t.Fatalf("expected the pause menu to open, got %T", g.Scene())
+	}
+	player := g.Player
+	enemies := append([]Enemy(nil), g.Enemies...)
+	projectiles := append([]Projectile(nil), g.Projectiles...)
+
+	step(t, g, 59)
+	if g.Player.X != player.X || g.Player.FrameNum != player.FrameNum || g.Player.FrameDur != player.FrameDur {
+		t.Errorf("expected the player and their animation to be frozen while paused, got %+v", g.Player)
+	}
+	if !reflect.DeepEqual(g.Enemies, enemies) || !reflect.DeepEqual(g.Projectiles, projectiles) {
+		t.Error("expected enemies and projectiles to be frozen while paused")
+	}
+
+	step(t, g, 4)
+	settings, ok := g.Scene().(*SettingsScene)
+	if !ok {
+		t.Fatalf("expected the settings menu to open, got %T", g.Scene())
+	}
+	step(t, g, 2)
+	if !g.Settings.Fullscreen || settings.Menu.Options[0] != "Fullscreen: On" {
+		t.Errorf("expected fullscreen to be turned on, got %+v and %v", g.Settings, settings.Menu.Options)
+	}
+
+	step(t, g, 2)
+	if _, ok := g.Scene().(*PauseScene); !ok {
+		t.Fatalf("expected the pause action to go back to the pause menu, got %T", g.Scene())
+	}
+	step(t, g, 2)
+	if _, ok := g.Scene().(*PlayScene); !ok || len(g.Scenes) != 1 {
+		t.Errorf("expected the pause action to resume the game, got %v", g.Scenes)
+	}
+}
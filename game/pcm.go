@@ -0,0 +1,264 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jfreymuth/oggvorbis"
+)
+
+// SampleRate is the sample rate sounds are decoded and played at
+const SampleRate = 44100
+
+// pcmFrameSize is the number of bytes in a frame of PCM, which is a 16-bit sample for each of the two channels
+const pcmFrameSize = 4
+
+// PCM is a decoded sound as 16-bit little-endian stereo samples at SampleRate, which is the format ebiten plays
+type PCM struct {
+	*bytes.Reader
+}
+
+// Length returns the size of the sound in bytes
+func (p *PCM) Length() int64 {
+	return p.Size()
+}
+
+// DecodePCM decodes an OGG or WAV file, converting it to stereo at SampleRate
+func DecodePCM(path string) (*PCM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []float32
+	var channels, rate int
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ogg":
+		var format *oggvorbis.Format
+		samples, format, err = oggvorbis.ReadAll(bytes.NewReader(data))
+		if format != nil {
+			channels, rate = format.Channels, format.SampleRate
+		}
+	case ".wav":
+		samples, channels, rate, err = decodeWAV(data)
+	default:
+		return nil, fmt.Errorf("%s: must be an OGG or WAV file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if channels != 1 && channels != 2 {
+		return nil, fmt.Errorf("%s: must be mono or stereo, got %d channels", path, channels)
+	}
+	if rate <= 0 {
+		return nil, fmt.Errorf("%s: invalid sample rate %d", path, rate)
+	}
+	return &PCM{Reader: bytes.NewReader(encodePCM(samples, channels, rate))}, nil
+}
+
+// decodeWAV decodes the samples of an uncompressed 8 or 16-bit WAV file into interleaved samples from -1 to 1
+func decodeWAV(data []byte) (samples []float32, channels int, rate int, err error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, 0, errors.New("not a WAV file")
+	}
+	var bits int
+	var pcm []byte
+	for chunk := data[12:]; len(chunk) >= 8; {
+		id := string(chunk[:4])
+		size := int(binary.LittleEndian.Uint32(chunk[4:8]))
+		if size > len(chunk)-8 {
+			return nil, 0, 0, fmt.Errorf("%q chunk is cut off", id)
+		}
+		body := chunk[8 : 8+size]
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, 0, errors.New("fmt chunk is too short")
+			}
+			if format := binary.LittleEndian.Uint16(body[0:2]); format != 1 {
+				return nil, 0, 0, fmt.Errorf("must be uncompressed PCM, got format %d", format)
+			}
+			channels = int(binary.LittleEndian.Uint16(body[2:4]))
+			rate = int(binary.LittleEndian.Uint32(body[4:8]))
+			bits = int(binary.LittleEndian.Uint16(body[14:16]))
+		case "data":
+			pcm = body
+		}
+		// Chunks are padded to an even size
+		chunk = chunk[Min(8+size+size%2, len(chunk)):]
+	}
+	if channels == 0 {
+		return nil, 0, 0, errors.New("missing fmt chunk")
+	}
+	if pcm == nil {
+		return nil, 0, 0, errors.New("missing data chunk")
+	}
+
+	switch bits {
+	case 8:
+		for _, b := range pcm {
+			samples = append(samples, float32(int(b)-128)/128)
+		}
+	case 16:
+		for i := 0; i+1 < len(pcm); i += 2 {
+			samples = append(samples, float32(int16(binary.LittleEndian.Uint16(pcm[i:])))/32768)
+		}
+	default:
+		return nil, 0, 0, fmt.Errorf("must be 8 or 16-bit, got %d-bit", bits)
+	}
+	return samples, channels, rate, nil
+}
+
+// encodePCM converts interleaved samples from -1 to 1 into 16-bit stereo PCM at SampleRate.
+// Mono sounds play on both channels, and other sample rates are resampled linearly.
+func encodePCM(samples []float32, channels, rate int) []byte {
+	frames := len(samples) / channels
+	sample := func(frame, channel int) float64 {
+		return float64(samples[Min(frame, frames-1)*channels+Min(channel, channels-1)])
+	}
+
+	out := make([]byte, 0, frames*SampleRate/rate*pcmFrameSize)
+	for i := 0; i < frames*SampleRate/rate; i++ {
+		pos := float64(i) * float64(rate) / SampleRate
+		frame := int(pos)
+		t := pos - float64(frame)
+		for c := 0; c < 2; c++ {
+			v := sample(frame, c)*(1-t) + sample(frame+1, c)*t
+			out = binary.LittleEndian.AppendUint16(out, uint16(clampSample(v*32767)))
+		}
+	}
+	return out
+}
+
+// clampSample rounds a sample to a 16-bit sample, clipping it if it is too loud
+func clampSample(v float64) int16 {
+	return int16(math.Max(math.MinInt16, math.Min(math.MaxInt16, math.Round(v))))
+}
+
+// Loop plays a PCM sound over and over, starting again from the beginning at its end
+type Loop struct {
+	PCM *PCM
+}
+
+func (l *Loop) Read(p []byte) (int, error) {
+	if l.PCM.Length() == 0 {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) {
+		m, err := l.PCM.Read(p[n:])
+		n += m
+		if err == io.EOF {
+			if _, err := l.PCM.Seek(0, io.SeekStart); err != nil {
+				return n, err
+			}
+		} else if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Seek seeks within the sound, wrapping positions past the end around to the beginning
+func (l *Loop) Seek(offset int64, whence int) (int64, error) {
+	pos, err := l.PCM.Seek(offset, whence)
+	if err != nil || l.PCM.Length() == 0 {
+		return pos, err
+	}
+	return l.PCM.Seek(pos%l.PCM.Length(), io.SeekStart)
+}
+
+// DecodeSoundFile decodes an OGG or WAV file into a sound played without a sound device
+func DecodeSoundFile(path string, loop bool) (Sound, error) {
+	pcm, err := DecodePCM(path)
+	if err != nil {
+		return nil, err
+	}
+	var source io.ReadSeeker = pcm
+	if loop {
+		source = &Loop{PCM: pcm}
+	}
+	return &PCMSound{Path: path, Loop: loop, Source: source, Volume: 1}, nil
+}
+
+// PCMSound is a sound played without a sound device. Reading it gives the samples a sound device would play.
+type PCMSound struct {
+	Path    string
+	Loop    bool
+	Source  io.ReadSeeker // The decoded sound, looped forever if Loop is true
+	Playing bool
+	Plays   int     // How many times the sound has been started from the beginning
+	Volume  float64 // The volume the sound is played at
+}
+
+func (s *PCMSound) Play() {
+	if !s.Playing {
+		s.Playing = true
+		s.Plays++
+	}
+}
+
+func (s *PCMSound) Pause() {
+	s.Playing = false
+}
+
+func (s *PCMSound) Rewind() error {
+	s.Playing = false
+	_, err := s.Source.Seek(0, io.SeekStart)
+	return err
+}
+
+func (s *PCMSound) IsPlaying() bool {
+	return s.Playing
+}
+
+func (s *PCMSound) SetVolume(volume float64) {
+	s.Volume = volume
+}
+
+// Read fills p with the next samples of the sound at its volume, or with silence while it isn't playing.
+// A sound that isn't looped stops playing at its end.
+func (s *PCMSound) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	if !s.Playing {
+		return len(p), nil
+	}
+	n, err := io.ReadFull(s.Source, p[:len(p)/2*2])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		s.Playing = false
+	} else if err != nil {
+		return 0, err
+	}
+	for i := 0; i+1 < n; i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(p[i:]))) * s.Volume
+		binary.LittleEndian.PutUint16(p[i:], uint16(clampSample(v)))
+	}
+	return len(p), nil
+}
+
+// Mix fills p with the sum of the next samples of sounds, as a sound device would play them together
+func Mix(p []byte, sounds ...*PCMSound) error {
+	mixed := make([]float64, len(p)/2)
+	buf := make([]byte, len(mixed)*2)
+	for _, s := range sounds {
+		if _, err := s.Read(buf); err != nil {
+			return err
+		}
+		for i := range mixed {
+			mixed[i] += float64(int16(binary.LittleEndian.Uint16(buf[i*2:])))
+		}
+	}
+	for i, v := range mixed {
+		binary.LittleEndian.PutUint16(p[i*2:], uint16(clampSample(v)))
+	}
+	return nil
+}
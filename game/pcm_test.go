@@ -0,0 +1,210 @@
+package game
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeWAV writes an uncompressed WAV file of interleaved samples
+func writeWAV(t *testing.T, path string, channels, rate, bits int, samples []int) {
+	t.Helper()
+	var data bytes.Buffer
+	for _, v := range samples {
+		if bits == 8 {
+			data.WriteByte(byte(v))
+		} else {
+			binary.Write(&data, binary.LittleEndian, int16(v))
+		}
+	}
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	binary.Write(&b, binary.LittleEndian, uint32(36+data.Len()))
+	b.WriteString("WAVEfmt ")
+	for _, v := range []interface{}{
+		uint32(16), uint16(1), uint16(channels), uint32(rate), uint32(rate * channels * bits / 8), uint16(channels * bits / 8), uint16(bits),
+	} {
+		binary.Write(&b, binary.LittleEndian, v)
+	}
+	b.WriteString("data")
+	binary.Write(&b, binary.LittleEndian, uint32(data.Len()))
+	b.Write(data.Bytes())
+	if err := os.WriteFile(path, b.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// frames reads n stereo frames from r
+func frames(t *testing.T, r io.Reader, n int) [][2]int16 {
+	t.Helper()
+	p := make([]byte, n*pcmFrameSize)
+	if _, err := io.ReadFull(r, p); err != nil {
+		t.Fatal(err)
+	}
+	out := make([][2]int16, n)
+	for i := range out {
+		out[i][0] = int16(binary.LittleEndian.Uint16(p[i*4:]))
+		out[i][1] = int16(binary.LittleEndian.Uint16(p[i*4+2:]))
+	}
+	return out
+}
+
+func TestDecodeWAV(t *testing.T) {
+	dir := t.TempDir()
+
+	// Mono 8-bit sound at half the sample rate plays on both channels, with a frame in between each of its own
+	path := filepath.Join(dir, "mono.wav")
+	writeWAV(t, path, 1, SampleRate/2, 8, []int{128 + 64, 128 - 64})
+	pcm, err := DecodePCM(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pcm.Length() != 4*pcmFrameSize {
+		t.Fatalf("expected 4 frames at the full sample rate, got %d bytes", pcm.Length())
+	}
+	want := [][2]int16{{16384, 16384}, {0, 0}, {-16384, -16384}, {-16384, -16384}}
+	if got := frames(t, pcm, 4); got[0] != want[0] || got[1] != want[1] || got[2] != want[2] || got[3] != want[3] {
+		t.Errorf("expected frames %v, got %v", want, got)
+	}
+
+	// Stereo 16-bit sound at the sample rate is decoded as it is
+	path = filepath.Join(dir, "stereo.wav")
+	writeWAV(t, path, 2, SampleRate, 16, []int{1000, -1000, 2000, -2000})
+	if pcm, err = DecodePCM(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := frames(t, pcm, 2); got[0] != [2]int16{1000, -1000} || got[1] != [2]int16{2000, -2000} {
+		t.Errorf("expected the stereo frames as they are, got %v", got)
+	}
+
+	// The sounds of the game decode
+	if _, err := DecodePCM("../sounds/field.wav"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string][]byte{
+		"garbage.wav": []byte("not a wav file"),
+		"garbage.ogg": []byte("OggS but not really"),
+		"cut.wav":     []byte("RIFF\x00\x00\x00\x00WAVEdata\xff\x00\x00\x00"),
+		"sound.mp3":   []byte("ID3"),
+	}
+	for name, data := range tests {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeWAV(t, filepath.Join(dir, "24bit.wav"), 1, SampleRate, 24, nil)
+	writeWAV(t, filepath.Join(dir, "surround.wav"), 6, SampleRate, 16, []int{0, 0, 0, 0, 0, 0})
+	for _, name := range []string{"garbage.wav", "garbage.ogg", "cut.wav", "sound.mp3", "24bit.wav", "surround.wav", "missing.wav"} {
+		if _, err := DecodePCM(filepath.Join(dir, name)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestLoopWrapsAround(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "loop.wav")
+	writeWAV(t, path, 1, SampleRate, 16, []int{100, 200, 300})
+	sound, err := DecodeSoundFile(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop := sound.(*PCMSound).Source
+
+	var got []int16
+	for _, f := range frames(t, loop, 7) {
+		got = append(got, f[0])
+	}
+	want := []int16{100, 200, 300, 100, 200, 300, 100}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected the loop to start again at its end, got %v", got)
+		}
+	}
+
+	if _, err := loop.Seek(4*pcmFrameSize, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if f := frames(t, loop, 1); f[0][0] != 200 {
+		t.Errorf("expected seeking past the end to wrap around, got %v", f)
+	}
+}
+
+func TestSoundStopsAtEnd(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "once.wav")
+	writeWAV(t, path, 1, SampleRate, 16, []int{100, 200})
+	sound, err := DecodeSoundFile(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := sound.(*PCMSound)
+
+	if f := frames(t, s, 1); f[0][0] != 0 {
+		t.Errorf("expected silence before the sound plays, got %v", f)
+	}
+	s.Play()
+	if f := frames(t, s, 3); f[0][0] != 100 || f[1][0] != 200 || f[2][0] != 0 || s.IsPlaying() {
+		t.Errorf("expected the sound to play once then stop, got %v and playing %v", f, s.IsPlaying())
+	}
+	if err := s.Rewind(); err != nil {
+		t.Fatal(err)
+	}
+	s.Play()
+	if f := frames(t, s, 1); f[0][0] != 100 || s.Plays != 2 {
+		t.Errorf("expected the rewound sound to play from the beginning, got %v", f)
+	}
+}
+
+func TestChannelVolumesMix(t *testing.T) {
+	dir := t.TempDir()
+	writeWAV(t, filepath.Join(dir, "theme.wav"), 1, SampleRate, 16, []int{10000, 10000})
+	writeWAV(t, filepath.Join(dir, "blip.wav"), 1, SampleRate, 16, []int{8000})
+	manifest := `[{"file": "theme.wav", "channel": "music", "volume": 0.6}, {"file": "blip.wav", "channel": "sfx"}]`
+	if err := os.WriteFile(filepath.Join(dir, "sounds.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sounds, err := LoadSounds(filepath.Join(dir, "sounds.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := newTestGame(t, "testdata/stump.json")
+	g.Sounds = sounds
+	g.Level.Music = "theme"
+	g.Settings.Volumes[MusicChannel] = 50
+	g.Settings.Volumes[EffectsChannel] = 25
+	UpdateAudio(g)
+	g.PlaySound("blip")
+
+	music, blip := silent(t, g, "theme"), silent(t, g, "blip")
+	var mixed bytes.Buffer
+	p := make([]byte, 3*pcmFrameSize)
+	if err := Mix(p, music, blip); err != nil {
+		t.Fatal(err)
+	}
+	mixed.Write(p)
+	// The music plays at 60% of half volume and the effect at a quarter volume, then the effect ends
+	want := []int16{3000 + 2000, 3000, 3000}
+	for i, f := range frames(t, &mixed, 3) {
+		if f[0] != want[i] || f[1] != want[i] {
+			t.Errorf("frame %d: expected %d on both channels, got %v", i, want[i], f)
+		}
+	}
+
+	// Changing the volume setting applies to the music that is playing
+	g.Settings.Volumes[MusicChannel] = 100
+	UpdateAudio(g)
+	if err := Mix(p, music, blip); err != nil {
+		t.Fatal(err)
+	}
+	mixed.Write(p)
+	if f := frames(t, &mixed, 1); f[0][0] != 6000 {
+		t.Errorf("expected the music at 60%% of full volume, got %v", f)
+	}
+}
@@ -0,0 +1,319 @@
+package game
+
+import (
+	"image"
+	"math"
+)
+
+type RenderTarget interface {
+	RenderSprite() Sprite
+	RenderImage() Image
+	RenderPosition() image.Point // The world position to draw the top left of the image at
+	RenderOrder() int
+	RenderHandle() image.Point
+	RenderX() int
+	RenderY() int
+	RenderVisible() bool // Whether to draw the target this frame, which is false while it flashes
+}
+
+// Turn is a transform of an image in quarter turns and mirrors, as the matrix [A B; C D] applied to points relative to
+// the point it is turned around
+type Turn struct {
+	A, B, C, D int
+}
+
+// Apply turns a point
+func (t Turn) Apply(p image.Point) image.Point {
+	return image.Pt(t.A*p.X+t.B*p.Y, t.C*p.X+t.D*p.Y)
+}
+
+// ApplyRect turns a rect, which covers the same pixels as the rect turned
+func (t Turn) ApplyRect(r image.Rectangle) image.Rectangle {
+	return image.Rectangle{Min: t.Apply(r.Min), Max: t.Apply(r.Max)}.Canon()
+}
+
+// Frame returns the sub-image of a single frame of the sprite.
+// Sprites cut from a larger sheet do not start at the origin, so frames are offset by the image bounds.
+func (s Sprite) Frame(n int) Image {
+	// sub-rect is the width of a frame times the frame number, plus the frame number for the 1-pixel buffer between frames
+	min := s.Image.Bounds().Min
+	x := min.X + s.FrameWidth*n + n
+	return s.Image.SubImage(image.Rect(x, min.Y, x+s.FrameWidth, min.Y+s.FrameHeight)).(Image)
+}
+
+func (p *Player) RenderSprite() Sprite {
+	return p.Sprite
+}
+
+func (p *Player) RenderImage() Image {
+	return p.Sprite.Frame(p.FrameNum)
+}
+
+func (p *Player) RenderPosition() image.Point {
+	// ebiten renders from the min vertex (top left). Offset by the frameheight and half the framewidth to emulate rendering from the "feet" of the sprite
+	return image.Pt(Pixel(p.X)-p.Sprite.FrameWidth/2, Pixel(p.Y)-p.Sprite.FrameHeight)
+}
+
+func (p *Player) RenderOrder() int {
+	return Pixel(p.Y)
+}
+
+func (p *Player) RenderHandle() image.Point {
+	return p.Sprite.Handles[p.FrameNum]
+}
+
+func (p *Player) RenderX() int {
+	return Pixel(p.X)
+}
+
+func (p *Player) RenderY() int {
+	return Pixel(p.Y)
+}
+
+func (p *Player) RenderVisible() bool {
+	return !IsFlashing(p.Invulnerable)
+}
+
+func (c *Character) RenderSprite() Sprite {
+	return c.Sprite
+}
+
+func (c *Character) RenderImage() Image {
+	return c.Sprite.Frame(c.FrameNum)
+}
+
+func (c *Character) RenderPosition() image.Point {
+	return image.Pt(Pixel(c.X)-c.Sprite.FrameWidth/2, Pixel(c.Y)-c.Sprite.FrameHeight)
+}
+
+func (c *Character) RenderOrder() int {
+	return Pixel(c.Y)
+}
+
+func (c *Character) RenderHandle() image.Point {
+	return c.Sprite.Handles[c.FrameNum]
+}
+
+func (c *Character) RenderX() int {
+	return Pixel(c.X)
+}
+
+func (c *Character) RenderY() int {
+	return Pixel(c.Y)
+}
+
+func (c *Character) RenderVisible() bool {
+	return true
+}
+
+func (e *Enemy) RenderSprite() Sprite {
+	return e.Sprite
+}
+
+func (e *Enemy) RenderImage() Image {
+	return e.Sprite.Frame(e.FrameNum)
+}
+
+func (e *Enemy) RenderPosition() image.Point {
+	return image.Pt(Pixel(e.X)-e.Sprite.FrameWidth/2, Pixel(e.Y)-e.Sprite.FrameHeight)
+}
+
+func (e *Enemy) RenderOrder() int {
+	return Pixel(e.Y)
+}
+
+func (e *Enemy) RenderHandle() image.Point {
+	return e.Sprite.Handles[e.FrameNum]
+}
+
+func (e *Enemy) RenderX() int {
+	return Pixel(e.X)
+}
+
+func (e *Enemy) RenderY() int {
+	return Pixel(e.Y)
+}
+
+func (e *Enemy) RenderVisible() bool {
+	return !IsFlashing(e.Invulnerable)
+}
+
+func (d *Doodad) RenderSprite() Sprite {
+	return d.Sprite
+}
+
+func (d *Doodad) RenderImage() Image {
+	return d.Sprite.Image
+}
+
+func (d *Doodad) RenderPosition() image.Point {
+	return image.Pt(d.X-d.Sprite.FrameWidth/2, d.Y-d.Sprite.FrameHeight)
+}
+
+func (d *Doodad) RenderOrder() int {
+	return d.Y
+}
+
+func (d *Doodad) RenderX() int {
+	return d.X
+}
+
+func (d *Doodad) RenderY() int {
+	return d.Y
+}
+
+func (d *Doodad) RenderVisible() bool {
+	return true
+}
+
+func (d *Doodad) RenderHandle() image.Point {
+	return d.Sprite.Handles[d.FrameNum]
+}
+
+func (t *Tile) RenderSprite() Sprite {
+	return t.Sprite
+}
+
+func (t *Tile) RenderImage() Image {
+	return t.Sprite.Image
+}
+
+func (t *Tile) RenderPosition() image.Point {
+	return image.Pt(t.X-t.Sprite.FrameWidth/2, t.Y-t.Sprite.FrameHeight)
+}
+
+func (t *Tile) RenderOrder() int {
+	if t.Collider {
+		return math.MinInt/2 + t.Layer
+	} else {
+		return math.MinInt + t.Layer
+	}
+}
+
+func (t *Tile) RenderX() int {
+	return t.X
+}
+
+func (t *Tile) RenderY() int {
+	return t.Y
+}
+
+func (t *Tile) RenderVisible() bool {
+	return true
+}
+
+func (t *Tile) RenderHandle() image.Point {
+	return t.Sprite.Handles[t.FrameNum]
+}
+
+func (w *Weapon) RenderSprite() Sprite {
+	return w.Sprite
+}
+
+func (w *Weapon) RenderImage() Image {
+	return w.Sprite.Image
+}
+
+// RenderPosition returns the top left of the weapon once it is turned to face the direction of the swing. The image
+// has to be drawn turned by Turn around its handle to cover the same rect as the hitbox.
+func (w *Weapon) RenderPosition() image.Point {
+	return w.Bounds().Min
+}
+
+func (w *Weapon) RenderOrder() int {
+	return w.Wielder.RenderOrder() + 1
+}
+
+func (w *Weapon) RenderHandle() image.Point {
+	return w.Sprite.Handles[0]
+}
+
+// Turn returns how the east-facing weapon sprite is turned to face the direction of the swing.
+// West mirrors the sprite, so the edge of the blade stays on the same side.
+func (w *Weapon) Turn() Turn {
+	switch w.Dir {
+	case West:
+		return Turn{A: -1, D: 1}
+	case North:
+		return Turn{B: 1, C: -1}
+	case South:
+		return Turn{B: -1, C: 1}
+	}
+	return Turn{A: 1, D: 1}
+}
+
+func (w *Weapon) RenderX() int {
+	return 0
+}
+
+func (w *Weapon) RenderY() int {
+	return 0
+}
+
+func (w *Weapon) RenderVisible() bool {
+	return w.Wielder.RenderVisible()
+}
+
+func (p *Projectile) RenderSprite() Sprite {
+	return p.Sprite
+}
+
+func (p *Projectile) RenderImage() Image {
+	return p.Sprite.Image
+}
+
+func (p *Projectile) RenderPosition() image.Point {
+	return image.Pt(Pixel(p.X)-p.Sprite.FrameWidth/2, Pixel(p.Y)-p.Sprite.FrameHeight)
+}
+
+func (p *Projectile) RenderOrder() int {
+	return Pixel(p.Y)
+}
+
+func (p *Projectile) RenderHandle() image.Point {
+	return p.Sprite.Handles[p.FrameNum]
+}
+
+func (p *Projectile) RenderX() int {
+	return Pixel(p.X)
+}
+
+func (p *Projectile) RenderY() int {
+	return Pixel(p.Y)
+}
+
+func (p *Projectile) RenderVisible() bool {
+	return true
+}
+
+func (p *Pickup) RenderSprite() Sprite {
+	return p.Sprite
+}
+
+func (p *Pickup) RenderImage() Image {
+	return p.Sprite.Frame(p.FrameNum)
+}
+
+func (p *Pickup) RenderPosition() image.Point {
+	return image.Pt(Pixel(p.X)-p.Sprite.FrameWidth/2, Pixel(p.Y)-p.Sprite.FrameHeight)
+}
+
+func (p *Pickup) RenderOrder() int {
+	return Pixel(p.Y)
+}
+
+func (p *Pickup) RenderHandle() image.Point {
+	return p.Sprite.Handles[p.FrameNum]
+}
+
+func (p *Pickup) RenderX() int {
+	return Pixel(p.X)
+}
+
+func (p *Pickup) RenderY() int {
+	return Pixel(p.Y)
+}
+
+func (p *Pickup) RenderVisible() bool {
+	return true
+}
@@ -0,0 +1,253 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"os"
+)
+
+// RecordingVersion is the version of the recording format written by Recorder
+const RecordingVersion = 1
+
+// ErrReplayEnded is returned from Replayer.Update once every recorded frame has been played
+var ErrReplayEnded = errors.New("replay ended")
+
+// RecordingJSON represents the json of a recorded play session.
+// The game is restarted from the saved start state with the recorded settings, then every frame's actions are played
+// back through Game.Update.
+type RecordingJSON struct {
+	Version  int             `json:"version"`
+	Settings SettingsJSON    `json:"settings"` // The settings the recording starts with, as the locale changes how dialogue plays
+	Start    json.RawMessage `json:"start"`    // The saved game the recording starts from
+	Frames   []FrameJSON     `json:"frames"`
+}
+
+// SettingsJSON represents the json of the settings picked in the settings menu
+type SettingsJSON struct {
+	Fullscreen bool              `json:"fullscreen"`
+	Volumes    [ChannelCount]int `json:"volumes"`
+	Locale     string            `json:"locale"`
+}
+
+// Save returns the saved state of the settings
+func (s Settings) Save() SettingsJSON {
+	return SettingsJSON(s)
+}
+
+// Settings returns the settings a save was made from
+func (v SettingsJSON) Settings() Settings {
+	return Settings(v)
+}
+
+// FrameJSON represents a single recorded update
+type FrameJSON struct {
+	Actions []string `json:"actions,omitempty"` // The names of the actions held down during the update
+	Hash    string   `json:"hash"`              // The hash of the game state after the update
+}
+
+// Divergence is the first frame of a replay where the game state doesn't match the recording
+type Divergence struct {
+	Frame    int      // The index of the frame in the recording
+	Actions  []string // The actions held down during the frame
+	Hash     string   // The hash of the replayed state
+	Recorded string   // The hash of the recorded state
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("replay diverged at frame %d with actions %v: state hash is %s, recorded %s", d.Frame, d.Actions, d.Hash, d.Recorded)
+}
+
+// StateHash returns a hash of everything that is saved about the game plus the camera.
+// Replays compare it every frame to check that identical input produces identical state.
+func (g *Game) StateHash() (string, error) {
+	h := fnv.New64a()
+	if err := g.Save(h); err != nil {
+		return "", err
+	}
+	fmt.Fprintf(h, "camera %d %d %d %d %d\n", g.Camera.X, g.Camera.Y, g.Camera.Room.X, g.Camera.Room.Y, g.Camera.Scroll)
+	return fmt.Sprintf("%016x", h.Sum64()), nil
+}
+
+// HeldActions returns the names of the actions an input is holding down
+func HeldActions(in Input) []string {
+	var held []string
+	for a := Action(0); a < ActionCount; a++ {
+		if in.IsPressed(a) {
+			held = append(held, a.String())
+		}
+	}
+	return held
+}
+
+// Recorder wraps a game and records the actions held down and the state hash of every update
+type Recorder struct {
+	*Game
+	Recording RecordingJSON
+}
+
+// NewRecorder starts recording a game from its current state and settings.
+// Save slots are kept in memory while recording, so a replay doesn't depend on what was on disk.
+func NewRecorder(g *Game) (*Recorder, error) {
+	var start bytes.Buffer
+	if err := g.Save(&start); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	// Restart from the saved state, so the recorded game starts exactly where a replay of it will
+	if err := g.Load(bytes.NewReader(start.Bytes())); err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	g.Slots = map[int][]byte{}
+	return &Recorder{
+		Game: g,
+		Recording: RecordingJSON{
+			Version:  RecordingVersion,
+			Settings: g.Settings.Save(),
+			Start:    start.Bytes(),
+		},
+	}, nil
+}
+
+func (r *Recorder) Update() error {
+	if err := r.Game.Update(); err != nil {
+		return err
+	}
+	hash, err := r.StateHash()
+	if err != nil {
+		return err
+	}
+	r.Recording.Frames = append(r.Recording.Frames, FrameJSON{
+		Actions: HeldActions(r.Input),
+		Hash:    hash,
+	})
+	return nil
+}
+
+// WriteFile writes the recording to a file
+func (r *Recorder) WriteFile(path string) error {
+	data, err := json.Marshal(r.Recording)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// ReadRecording reads a recording file
+func ReadRecording(path string) (*RecordingJSON, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var recording RecordingJSON
+	if err := json.Unmarshal(data, &recording); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+	if recording.Version != RecordingVersion {
+		return nil, fmt.Errorf("%s: unsupported recording version %d, expected %d", path, recording.Version, RecordingVersion)
+	}
+	return &recording, nil
+}
+
+// Script converts the recorded actions into frames for a ScriptedInput
+func (r *RecordingJSON) Script() ([][]Action, error) {
+	script := make([][]Action, len(r.Frames))
+	for i, f := range r.Frames {
+		for j, name := range f.Actions {
+			a, ok := ActionNames[name]
+			if !ok {
+				return nil, fmt.Errorf("frames[%d].actions[%d]: unknown action %q", i, j, name)
+			}
+			script[i] = append(script[i], a)
+		}
+	}
+	return script, nil
+}
+
+// Replayer wraps a game and plays a recording back through Game.Update, checking the state hash after every update
+type Replayer struct {
+	*Game
+	Recording  *RecordingJSON
+	Script     *ScriptedInput
+	Divergence *Divergence // The first frame that didn't match the recording, or nil
+}
+
+// NewReplayer restarts a game from the start of a recording with its settings and replaces its input with the recorded
+// actions. Save slots are kept in memory while replaying, as they were while recording.
+func NewReplayer(g *Game, recording *RecordingJSON) (*Replayer, error) {
+	script, err := recording.Script()
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	// The locale is set before loading, as the dialogue shown so far is cut to the length of the phrases of the locale
+	if err := g.SetLocale(recording.Settings.Locale); err != nil {
+		return nil, fmt.Errorf("replay: settings.locale: %w", err)
+	}
+	g.Settings = recording.Settings.Settings()
+	if err := g.Load(bytes.NewReader(recording.Start)); err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	g.Slots = map[int][]byte{}
+	r := &Replayer{
+		Game:      g,
+		Recording: recording,
+		Script:    &ScriptedInput{Frames: script},
+	}
+	g.Input = r.Script
+	return r, nil
+}
+
+// Step plays the next recorded frame. It returns false once every frame has been played.
+func (r *Replayer) Step() (bool, error) {
+	frame := r.Script.Frame
+	if frame >= len(r.Recording.Frames) {
+		return false, nil
+	}
+	if err := r.Game.Update(); err != nil {
+		return false, err
+	}
+	hash, err := r.StateHash()
+	if err != nil {
+		return false, err
+	}
+	recorded := r.Recording.Frames[frame]
+	if hash != recorded.Hash && r.Divergence == nil {
+		r.Divergence = &Divergence{
+			Frame:    frame,
+			Actions:  recorded.Actions,
+			Hash:     hash,
+			Recorded: recorded.Hash,
+		}
+	}
+	return true, nil
+}
+
+func (r *Replayer) Update() error {
+	more, err := r.Step()
+	if err != nil {
+		return err
+	}
+	if !more {
+		return ErrReplayEnded
+	}
+	return nil
+}
+
+// Verify plays the rest of the recording as fast as possible without drawing, and returns the first divergence if there is one
+func (r *Replayer) Verify() error {
+	for {
+		more, err := r.Step()
+		if err != nil {
+			return err
+		}
+		if !more {
+			break
+		}
+		if r.Divergence != nil {
+			return r.Divergence
+		}
+	}
+	return nil
+}
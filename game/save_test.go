@@ -0,0 +1,143 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSaveRoundTrip(t *testing.T) {
+	g := newTestGame(t, "../levels/field.tmj")
+	elder := &g.Characters[0]
+
+	// Answer no, then start talking again and stop partway through the phrase with the second option picked
+	talk(elder, 1)
+	elder.StartDialogue()
+	graph := g.DialogueGraphs["elder"]
+	if graph.NodeKey != "elder_no_fun" {
+		t.Fatalf("expected answering no to lead to elder_no_fun, got %q", graph.NodeKey)
+	}
+	graph.Nodes[graph.NodeKey].RuneNum = 5
+	elder.SelectOption(1)
+	g.InteractionTarget = elder
+	g.Player.X, g.Player.Y = 100, 120
+	g.Player.Health = 3
+	g.Player.Items["rupee"] = 7
+	g.Roll(10)
+
+	var first bytes.Buffer
+	if err := g.Save(&first); err != nil {
+		t.Fatal(err)
+	}
+	saved := first.String()
+	loaded := newTestGame(t, "../levels/field.tmj")
+	if err := loaded.Load(&first); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Variables["answeredNo"] != true {
+		t.Errorf("expected the elder's answer to survive a load, got %v", loaded.Variables)
+	}
+	graph = loaded.DialogueGraphs["elder"]
+	if node := graph.Nodes[graph.NodeKey]; graph.NodeKey != "elder_no_fun" || node.RuneNum != 5 || node.OptionNum != 1 {
+		t.Errorf("expected elder_no_fun 5 runes in with no picked, got %q %d runes and option %d", graph.NodeKey, node.RuneNum, node.OptionNum)
+	}
+	if loaded.InteractionTarget != &loaded.Characters[0] {
+		t.Errorf("expected the player to still be talking to the elder, got %v", loaded.InteractionTarget)
+	}
+	if loaded.Player.X != 100 || loaded.Player.Y != 120 || loaded.Player.Health != 3 || loaded.Player.Items["rupee"] != 7 {
+		t.Errorf("expected the player to be restored, got %+v", loaded.Player)
+	}
+
+	// Saving the loaded game gives the same save
+	var second bytes.Buffer
+	if err := loaded.Save(&second); err != nil {
+		t.Fatal(err)
+	}
+	if second.String() != saved {
+		t.Errorf("expected the same save after a load, got\n%s\nthen\n%s", saved, second.String())
+	}
+
+	// Picking the saved option carries on with the answer the elder remembers
+	loaded.Characters[0].AdvancePhrase()
+	if graph.NodeKey != "elder_no_fun" {
+		t.Errorf("expected picking no to stay on elder_no_fun, got %q", graph.NodeKey)
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json")
+	var b bytes.Buffer
+	if err := g.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	current := fmt.Sprintf(`"version": %d`, SaveVersion)
+	if !strings.Contains(b.String(), current) {
+		t.Fatalf("expected the save to have version %d, got %s", SaveVersion, b.String())
+	}
+
+	tests := map[string]string{
+		"future version": strings.Replace(b.String(), current, fmt.Sprintf(`"version": %d`, SaveVersion+1), 1),
+		"zero version":   strings.Replace(b.String(), current, `"version": 0`, 1),
+		"string version": strings.Replace(b.String(), current, `"version": "9"`, 1),
+		"no version":     strings.Replace(b.String(), current+",", "", 1),
+		"garbage":        "not a save",
+		"cut off":        b.String()[:b.Len()/2],
+	}
+	for name, save := range tests {
+		loaded := newTestGame(t, "testdata/stump.json")
+		loaded.Player.X = 1
+		if err := loaded.Load(strings.NewReader(save)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		if loaded.Player.X != 1 {
+			t.Errorf("%s: expected a rejected save to leave the game as it was", name)
+		}
+	}
+}
+
+func TestSavingFromPauseMenuLoadsIntoGame(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json")
+	g.PushScene(NewInventoryScene(g.Player.Items, g.Text))
+	g.PushScene(NewPauseScene())
+	g.PushScene(NewSettingsScene(g))
+
+	var b bytes.Buffer
+	if err := g.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newTestGame(t, "testdata/stump.json")
+	if err := loaded.Load(&b); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Scene().(*InventoryScene); !ok || len(loaded.Scenes) != 2 {
+		t.Errorf("expected the pause and settings menus to be left out of the save, got %v", loaded.Scenes)
+	}
+}
+
+func TestSavedInteractionIsTheCharacterTalkedTo(t *testing.T) {
+	g := newTestGame(t, "../levels/field.tmj", Hold(1, Interact), Hold(1))
+	// Another character far away comes after the elder
+	other := g.Characters[0]
+	other.X += 100
+	g.Characters = append(g.Characters, other)
+	g.IndexColliders()
+
+	// Stand just under the elder
+	elder := g.Characters[0].Hitbox(0, 0)
+	g.Player.X = g.Characters[0].X
+	g.Player.Y += float64(elder.Max.Y - g.Player.Hitbox(0, 0).Min.Y)
+	step(t, g, 2)
+	if g.InteractionTarget != &g.Characters[0] {
+		t.Fatalf("expected the player to talk to the elder, got %v", g.InteractionTarget)
+	}
+
+	var b bytes.Buffer
+	if err := g.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"interaction": 0`) {
+		t.Errorf("expected the save to name the elder as the character talked to, got %s", b.String())
+	}
+}
@@ -0,0 +1,154 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	StartOption = "Start"
+	GameTitle   = "grame"
+)
+
+// Scene is one screen of the game with its own updates and input handling, like the title screen, gameplay or a menu.
+// Scenes are kept on a stack and only the top scene is updated, so the scenes under it are suspended.
+// The main package draws each scene.
+type Scene interface {
+	Update(g *Game) error // Updates the scene while it is on top of the stack
+	Overlay() bool        // Returns true if the scenes under it are still drawn underneath
+}
+
+// Scene returns the scene on top of the stack, which is the one that is updated
+func (g *Game) Scene() Scene {
+	return g.Scenes[len(g.Scenes)-1]
+}
+
+// PushScene puts a scene on top of the stack, suspending the one under it
+func (g *Game) PushScene(s Scene) {
+	g.Scenes = append(g.Scenes, s)
+}
+
+// PopScene removes the scene on top of the stack, resuming the one under it
+func (g *Game) PopScene() {
+	g.Scenes[len(g.Scenes)-1] = nil
+	g.Scenes = g.Scenes[:len(g.Scenes)-1]
+}
+
+// DrawnScenes returns the scenes that are drawn, from the bottom up. That is the top scene and every scene under it
+// down to the first one that isn't an overlay.
+func (g *Game) DrawnScenes() []Scene {
+	i := len(g.Scenes) - 1
+	for i > 0 && g.Scenes[i].Overlay() {
+		i--
+	}
+	return g.Scenes[i:]
+}
+
+// PlayScene is the gameplay of walking around the world
+type PlayScene struct{}
+
+// Update runs a tick of the world
+func (s *PlayScene) Update(g *Game) error {
+	if g.Input.IsJustReleased(Pause) {
+		g.PushScene(NewPauseScene())
+		return nil
+	}
+	if g.Player.Dying {
+		UpdatePlayerDeath(g)
+		return nil
+	}
+
+	UpdateInteraction(g)
+	if g.InteractionTarget != nil {
+		return nil
+	}
+
+	// Everything but the camera is frozen while scrolling to another room
+	if !g.Camera.IsScrolling() {
+		UpdatePlayer(g)
+	}
+	UpdateCamera(g)
+	if g.Camera.IsScrolling() {
+		return nil
+	}
+	UpdateCheckpoints(g)
+
+	UpdateCharacters(g)
+	UpdateEnemies(g)
+	UpdateProjectiles(g)
+	UpdatePickups(g)
+	UpdateDamage(g)
+	UpdateDespawns(g)
+
+	if g.Input.IsJustReleased(Inventory) {
+		g.PushScene(NewInventoryScene(g.Player.Items, g.Text))
+	}
+	return nil
+}
+
+// Overlay returns false, as the world covers the whole screen
+func (s *PlayScene) Overlay() bool {
+	return false
+}
+
+// TitleScene is the title screen shown over the world when the game starts
+type TitleScene struct {
+	Menu *Menu
+}
+
+// NewTitleScene creates the title screen
+func NewTitleScene() *TitleScene {
+	return &TitleScene{Menu: &Menu{Title: GameTitle, Options: []string{StartOption, QuitOption}}}
+}
+
+// Update lets the player start the game, or returns ErrQuit if they quit
+func (s *TitleScene) Update(g *Game) error {
+	switch s.Menu.Update(g.Input) {
+	case StartOption:
+		g.PopScene()
+	case QuitOption:
+		return ErrQuit
+	}
+	return nil
+}
+
+// Overlay returns false, as the title screen hides the world
+func (s *TitleScene) Overlay() bool {
+	return false
+}
+
+// InventoryScene lists the items the player has collected over the suspended world
+type InventoryScene struct {
+	Menu *Menu
+}
+
+// NewInventoryScene creates an inventory listing items and how many of each there are, sorted by name.
+// The names of items are shown in the locale of text.
+func NewInventoryScene(items map[string]int, text func(string) string) *InventoryScene {
+	var names []string
+	for item := range items {
+		names = append(names, item)
+	}
+	sort.Strings(names)
+
+	m := &Menu{Title: text("Inventory"), Localized: true}
+	for _, item := range names {
+		m.Options = append(m.Options, fmt.Sprintf("%s x%d", text(item), items[item]))
+	}
+	return &InventoryScene{Menu: m}
+}
+
+// Update moves through the items, and closes the inventory when the inventory action is released again
+func (s *InventoryScene) Update(g *Game) error {
+	if g.Input.IsJustReleased(Inventory) {
+		g.PopScene()
+		return nil
+	}
+	s.Menu.Update(g.Input)
+	return nil
+}
+
+// Overlay returns true, as the inventory is drawn over the world
+func (s *InventoryScene) Overlay() bool {
+	return true
+}
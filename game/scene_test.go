@@ -0,0 +1,58 @@
+package game
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInventorySuspendsGameplay(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json", Hold(1, Inventory), Hold(1), Hold(30, MoveLeft), Hold(1, Inventory), Hold(1))
+	g.Player.Items["rupee"] = 3
+
+	step(t, g, 2)
+	inventory, ok := g.Scene().(*InventoryScene)
+	if !ok {
+		t.Fatalf("expected the inventory to open, got %T", g.Scene())
+	}
+	if len(inventory.Menu.Options) != 1 || inventory.Menu.Options[0] != "rupee x3" {
+		t.Errorf("expected the inventory to list 3 rupees, got %v", inventory.Menu.Options)
+	}
+	if drawn := g.DrawnScenes(); len(drawn) != 2 || drawn[0] != Scene(g.Scenes[0]) {
+		t.Errorf("expected gameplay to be drawn under the inventory, got %v", drawn)
+	}
+
+	x := g.Player.X
+	step(t, g, 30)
+	if g.Player.X != x {
+		t.Errorf("expected the player to stay still while the inventory is open, moved from %v to %v", x, g.Player.X)
+	}
+
+	step(t, g, 2)
+	if _, ok := g.Scene().(*PlayScene); !ok || len(g.Scenes) != 1 {
+		t.Errorf("expected the inventory to close back to gameplay, got %v", g.Scenes)
+	}
+}
+
+func TestScenesAreSaved(t *testing.T) {
+	g := newTestGame(t, "testdata/stump.json")
+	g.PushScene(NewTitleScene())
+	g.Scene().(*TitleScene).Menu.Selected = 1
+
+	var b bytes.Buffer
+	if err := g.Save(&b); err != nil {
+		t.Fatal(err)
+	}
+	loaded := newTestGame(t, "testdata/stump.json")
+	if err := loaded.Load(&b); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Scenes) != 2 {
+		t.Fatalf("expected gameplay and the title screen, got %v", loaded.Scenes)
+	}
+	if title, ok := loaded.Scene().(*TitleScene); !ok || title.Menu.Selected != 1 {
+		t.Errorf("expected the title screen with quit selected, got %+v", loaded.Scene())
+	}
+	if drawn := loaded.DrawnScenes(); len(drawn) != 1 {
+		t.Errorf("expected only the title screen to be drawn, got %v", drawn)
+	}
+}
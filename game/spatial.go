@@ -0,0 +1,140 @@
+package game
+
+import (
+	"image"
+)
+
+// DefaultCellSize is the size of a spatial hash cell in pixels. It is about the size of the sprites that collide.
+const DefaultCellSize = 32
+
+// SpatialHash indexes colliders by the grid cells their hitboxes cover, so overlap queries only check the colliders nearby.
+// Colliders are indexed by pointer, so it has to be rebuilt when the slices they point into change.
+type SpatialHash struct {
+	CellSize int
+	Cells    map[image.Point][]Collider
+	Bounds   map[Collider]image.Rectangle // The hitbox each collider was indexed with
+}
+
+// NewSpatialHash creates an empty spatial hash with square cells of the given size
+func NewSpatialHash(cellSize int) *SpatialHash {
+	return &SpatialHash{
+		CellSize: cellSize,
+		Cells:    map[image.Point][]Collider{},
+		Bounds:   map[Collider]image.Rectangle{},
+	}
+}
+
+// CellRange returns the cells covered by a rectangle, as a rectangle of cell coordinates
+func (h *SpatialHash) CellRange(r image.Rectangle) image.Rectangle {
+	return image.Rect(
+		FloorDiv(r.Min.X, h.CellSize),
+		FloorDiv(r.Min.Y, h.CellSize),
+		FloorDiv(r.Max.X-1, h.CellSize)+1,
+		FloorDiv(r.Max.Y-1, h.CellSize)+1,
+	)
+}
+
+// Insert adds a collider to the cells its hitbox covers. Colliders with an empty hitbox, like non-collider tiles, are skipped.
+func (h *SpatialHash) Insert(c Collider) {
+	r := c.Hitbox(0, 0)
+	if r.Empty() {
+		return
+	}
+	h.Bounds[c] = r
+	cells := h.CellRange(r)
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			p := image.Pt(x, y)
+			h.Cells[p] = append(h.Cells[p], c)
+		}
+	}
+}
+
+// Remove takes a collider out of the index
+func (h *SpatialHash) Remove(c Collider) {
+	r, ok := h.Bounds[c]
+	if !ok {
+		return
+	}
+	delete(h.Bounds, c)
+	cells := h.CellRange(r)
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			p := image.Pt(x, y)
+			cell := h.Cells[p]
+			for i, v := range cell {
+				if v == c {
+					// Keep the order of the cell so queries stay deterministic
+					cell = append(cell[:i], cell[i+1:]...)
+					break
+				}
+			}
+			if len(cell) == 0 {
+				delete(h.Cells, p)
+			} else {
+				h.Cells[p] = cell
+			}
+		}
+	}
+}
+
+// Update re-indexes a collider after it moved or changed size. It only touches the cells if the collider moved into different ones.
+// Colliders that aren't in the index, like projectiles, are ignored.
+func (h *SpatialHash) Update(c Collider) {
+	r, ok := h.Bounds[c]
+	if !ok {
+		return
+	}
+	if h.CellRange(r) == h.CellRange(c.Hitbox(0, 0)) {
+		h.Bounds[c] = c.Hitbox(0, 0)
+		return
+	}
+	h.Remove(c)
+	h.Insert(c)
+}
+
+// Query returns every collider whose hitbox overlaps r, in a stable order
+func (h *SpatialHash) Query(r image.Rectangle) []Collider {
+	var found []Collider
+	if r.Empty() {
+		return found
+	}
+	cells := h.CellRange(r)
+	for y := cells.Min.Y; y < cells.Max.Y; y++ {
+		for x := cells.Min.X; x < cells.Max.X; x++ {
+			for _, c := range h.Cells[image.Pt(x, y)] {
+				overlap := r.Intersect(c.Hitbox(0, 0))
+				if overlap.Empty() {
+					continue
+				}
+				// A collider can cover several cells, so only report it from the cell the overlap starts in
+				if FloorDiv(overlap.Min.X, h.CellSize) == x && FloorDiv(overlap.Min.Y, h.CellSize) == y {
+					found = append(found, c)
+				}
+			}
+		}
+	}
+	return found
+}
+
+// IndexColliders rebuilds the spatial hash of the player and everything that blocks movement.
+// It is called whenever the tile, doodad, character or enemy slices are replaced or resized.
+func (g *Game) IndexColliders() {
+	g.Colliders = NewSpatialHash(DefaultCellSize)
+	g.Colliders.Insert(&g.Player)
+	for i := range g.Tiles {
+		g.Colliders.Insert(&g.Tiles[i])
+	}
+	for i := range g.Doodads {
+		g.Colliders.Insert(&g.Doodads[i])
+	}
+	for i := range g.Characters {
+		g.Colliders.Insert(&g.Characters[i])
+	}
+	for i := range g.Enemies {
+		// Dying enemies no longer block anything
+		if !g.Enemies[i].Dying {
+			g.Colliders.Insert(&g.Enemies[i])
+		}
+	}
+}
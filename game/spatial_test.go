@@ -0,0 +1,195 @@
+package game
+
+import (
+	"fmt"
+	"image"
+	"math/rand"
+	"testing"
+)
+
+// benchmarkWorld creates a game with a grass floor of n tiles, with every tenth tile a collider, and a number of enemies spread over it
+func benchmarkWorld(n, enemies int) *Game {
+	r := rand.New(rand.NewSource(1))
+	grass := Sprite{Key: "grass", FrameWidth: 16, FrameHeight: 16, FrameLen: 1}
+	wizard := Sprite{Key: "wizard", FrameWidth: 16, FrameHeight: 24, FrameLen: 1}
+	columns := 64
+	g := &Game{}
+	for i := 0; i < n; i++ {
+		g.Tiles = append(g.Tiles, Tile{
+			X:        8 + (i%columns)*16,
+			Y:        16 + (i/columns)*16,
+			Sprite:   grass,
+			Collider: i%10 == 0,
+		})
+	}
+	rows := (n + columns - 1) / columns
+	for i := 0; i < enemies; i++ {
+		g.Enemies = append(g.Enemies, Enemy{
+			X:      float64(r.Intn(columns * 16)),
+			Y:      float64(r.Intn(rows * 16)),
+			Sprite: wizard,
+		})
+	}
+	g.IndexColliders()
+	return g
+}
+
+// linearQuery finds overlapping colliders the way movement did before the spatial hash, by scanning every slice
+func linearQuery(g *Game, r image.Rectangle) []Collider {
+	var found []Collider
+	for i := range g.Tiles {
+		if r.Overlaps(g.Tiles[i].Hitbox(0, 0)) {
+			found = append(found, &g.Tiles[i])
+		}
+	}
+	for i := range g.Enemies {
+		if r.Overlaps(g.Enemies[i].Hitbox(0, 0)) {
+			found = append(found, &g.Enemies[i])
+		}
+	}
+	return found
+}
+
+// sameColliders returns true if both slices have the same colliders, in any order
+func sameColliders(a, b []Collider) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	count := map[Collider]int{}
+	for _, c := range a {
+		count[c]++
+	}
+	for _, c := range b {
+		count[c]--
+	}
+	for _, n := range count {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSpatialHashMatchesLinearScan(t *testing.T) {
+	g := benchmarkWorld(2000, 40)
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 1000; i++ {
+		x, y := r.Intn(1100)-40, r.Intn(600)-40
+		rect := image.Rect(x, y, x+1+r.Intn(64), y+1+r.Intn(64))
+		if got, want := g.Colliders.Query(rect), linearQuery(g, rect); !sameColliders(got, want) {
+			t.Fatalf("query %v: got %d colliders, want %d", rect, len(got), len(want))
+		}
+	}
+}
+
+func TestSpatialHashUpdate(t *testing.T) {
+	g := benchmarkWorld(64, 1)
+	e := &g.Enemies[0]
+	e.X, e.Y = 2000, 2000
+	g.Colliders.Update(e)
+
+	if got := g.Colliders.Query(e.Hitbox(0, 0)); len(got) != 1 || got[0] != Collider(e) {
+		t.Errorf("expected to find only the moved enemy at its new position, got %v", got)
+	}
+	for p, cell := range g.Colliders.Cells {
+		for _, c := range cell {
+			if c == Collider(e) && !p.In(g.Colliders.CellRange(e.Hitbox(0, 0))) {
+				t.Errorf("moved enemy is still indexed in cell %v", p)
+			}
+		}
+	}
+
+	g.Colliders.Remove(e)
+	if got := g.Colliders.Query(e.Hitbox(0, 0)); len(got) != 0 {
+		t.Errorf("expected no colliders after removing the enemy, got %v", got)
+	}
+}
+
+func TestSpatialHashNegativeCoordinates(t *testing.T) {
+	h := NewSpatialHash(DefaultCellSize)
+	d := &Doodad{X: -40, Y: -40, Sprite: Sprite{FrameWidth: 16, FrameHeight: 16}}
+	h.Insert(d)
+	if got := h.Query(image.Rect(-50, -50, -40, -40)); len(got) != 1 {
+		t.Errorf("expected to find the doodad left of and above the origin, got %v", got)
+	}
+	if got := h.Query(image.Rect(-32, -32, 0, 0)); len(got) != 0 {
+		t.Errorf("expected nothing in the cell next to the doodad, got %v", got)
+	}
+}
+
+var benchmarkSizes = []struct{ tiles, enemies int }{
+	{400, 1},
+	{4000, 24},
+	{16000, 48},
+}
+
+// benchmarkRects are the hitboxes of a player sized collider at positions across the world
+func benchmarkRects(g *Game) []image.Rectangle {
+	var rects []image.Rectangle
+	for i := 0; i < 64; i++ {
+		t := g.Tiles[(i*997)%len(g.Tiles)]
+		rects = append(rects, image.Rect(t.X-9, t.Y-11, t.X+9, t.Y))
+	}
+	return rects
+}
+
+func BenchmarkLinearQuery(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tiles=%d/enemies=%d", size.tiles, size.enemies), func(b *testing.B) {
+			g := benchmarkWorld(size.tiles, size.enemies)
+			rects := benchmarkRects(g)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				linearQuery(g, rects[i%len(rects)])
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialHashQuery(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tiles=%d/enemies=%d", size.tiles, size.enemies), func(b *testing.B) {
+			g := benchmarkWorld(size.tiles, size.enemies)
+			rects := benchmarkRects(g)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				g.Colliders.Query(rects[i%len(rects)])
+			}
+		})
+	}
+}
+
+func BenchmarkSpatialHashUpdate(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tiles=%d/enemies=%d", size.tiles, size.enemies), func(b *testing.B) {
+			g := benchmarkWorld(size.tiles, size.enemies)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Walk every enemy one pixel, back and forth, like AdvanceBehavior does
+				for j := range g.Enemies {
+					if i%64 < 32 {
+						g.Enemies[j].X++
+					} else {
+						g.Enemies[j].X--
+					}
+					g.Colliders.Update(&g.Enemies[j])
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUpdateEnemies(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprintf("tiles=%d/enemies=%d", size.tiles, size.enemies), func(b *testing.B) {
+			g := benchmarkWorld(size.tiles, size.enemies)
+			g.Player = Player{X: 512, Y: 256, Sprite: Sprite{FrameWidth: 16, FrameHeight: 21}}
+			g.Sprites = map[string]Sprite{}
+			g.CollisionRules = DefaultCollisionRules()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				UpdateEnemies(g)
+			}
+		})
+	}
+}
@@ -0,0 +1,4 @@
+<?xml version="1.0" encoding="UTF-8"?>
+<tileset version="1.10" tiledversion="1.10.2" name="tiles" tilewidth="16" tileheight="16" spacing="2" margin="1" tilecount="2" columns="2">
+ <image source="tiles.png" width="36" height="18"/>
+</tileset>
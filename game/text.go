@@ -0,0 +1,52 @@
+package game
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// RunePrefix returns the first n runes of s, or all of s if it is shorter
+func RunePrefix(s string, n int) string {
+	for i := range s {
+		if n == 0 {
+			return s[:i]
+		}
+		n--
+	}
+	return s
+}
+
+// NextCluster returns the number of runes of s up to the end of the character starting at rune n, so a character made
+// of several runes, like a letter with combining accents or an emoji sequence, is shown at once. It returns the rune
+// count of s if n is at or past the end.
+func NextCluster(s string, n int) int {
+	runes := []rune(s)
+	if n >= len(runes) {
+		return len(runes)
+	}
+	first := runes[n]
+	n++
+	for n < len(runes) {
+		r := runes[n]
+		switch {
+		case r == '\u200d' && n+1 < len(runes):
+			// A zero width joiner joins the next rune into the character
+			n += 2
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc, unicode.Variation_Selector) || (r >= 0x1f3fb && r <= 0x1f3ff):
+			n++
+		case first == '\r' && r == '\n':
+			n++
+		case unicode.Is(unicode.Regional_Indicator, first) && unicode.Is(unicode.Regional_Indicator, r):
+			// Flags are pairs of regional indicators
+			return n + 1
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// RuneCount returns the number of runes in s
+func RuneCount(s string) int {
+	return utf8.RuneCountInString(s)
+}
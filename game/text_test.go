@@ -0,0 +1,46 @@
+package game
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNextCluster(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []int // The rune counts the typewriter stops at
+	}{
+		{"abc", []int{1, 2, 3}},
+		{"été", []int{1, 2, 3}},
+		{"e\u0301te\u0301", []int{2, 3, 5}},
+		{"楽しい", []int{1, 2, 3}},
+		{"👍🏽!", []int{2, 3}},
+		{"👩\u200d💻x", []int{3, 4}},
+		{"🇫🇷🇯🇵", []int{2, 4}},
+	}
+	for _, test := range tests {
+		var got []int
+		for n := 0; n < RuneCount(test.s); {
+			n = NextCluster(test.s, n)
+			got = append(got, n)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("NextCluster(%q) stops at %v, want %v", test.s, got, test.want)
+		}
+	}
+}
+
+func TestTypewriterAdvancesByCharacter(t *testing.T) {
+	phrase := "Ça va ? 楽しい！"
+	graph := &DialogueGraph{Nodes: map[string]*DialogueNode{"a": {Phrase: phrase, End: true}}, NodeKey: "a", RootKey: "a"}
+	c := &Character{DialogueGraphs: map[string]*DialogueGraph{"a": graph}, DialogueKey: "a"}
+
+	var shown []string
+	for i := 0; i < RuneCount(phrase)+2; i++ {
+		c.AdvanceRune()
+		shown = append(shown, c.Dialogue())
+	}
+	if shown[0] != "Ç" || shown[8] != "Ça va ? 楽" || shown[len(shown)-1] != phrase {
+		t.Errorf("expected the phrase to be shown a character at a time, got %q", shown)
+	}
+}
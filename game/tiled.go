@@ -0,0 +1,545 @@
+package game
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// TiledGIDMask strips the flip and rotation flags Tiled stores in the high bits of a global tile id
+const TiledGIDMask = 0x0fffffff
+
+// TiledMap represents a map authored in the Tiled editor, read from either a .tmx or a .tmj file.
+// Only orthogonal, finite maps are supported.
+type TiledMap struct {
+	Orientation string          `xml:"orientation,attr" json:"orientation"`
+	Infinite    bool            `xml:"infinite,attr" json:"infinite"`
+	Width       int             `xml:"width,attr" json:"width"`
+	Height      int             `xml:"height,attr" json:"height"`
+	TileWidth   int             `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight  int             `xml:"tileheight,attr" json:"tileheight"`
+	Tilesets    []TiledTileset  `xml:"tileset" json:"tilesets"`
+	Layers      []TiledLayer    `xml:",any" json:"layers"`
+	Properties  []TiledProperty `xml:"properties>property" json:"properties"`
+}
+
+// TiledTileset represents a tileset embedded in a map or referenced from an external .tsx or .tsj file
+type TiledTileset struct {
+	FirstGID    uint32      `xml:"firstgid,attr" json:"firstgid"`
+	Source      string      `xml:"source,attr" json:"source"`
+	Name        string      `xml:"name,attr" json:"name"`
+	TileWidth   int         `xml:"tilewidth,attr" json:"tilewidth"`
+	TileHeight  int         `xml:"tileheight,attr" json:"tileheight"`
+	TileCount   int         `xml:"tilecount,attr" json:"tilecount"`
+	Columns     int         `xml:"columns,attr" json:"columns"`
+	Spacing     int         `xml:"spacing,attr" json:"spacing"`
+	Margin      int         `xml:"margin,attr" json:"margin"`
+	Image       TiledImage  `xml:"image" json:"-"`
+	ImageSource string      `xml:"-" json:"image"`
+	Tiles       []TiledTile `xml:"tile" json:"tiles"`
+	Dir         string      `xml:"-" json:"-"` // The directory image sources are relative to
+}
+
+// TiledTile represents the per-tile data of a tileset, such as custom properties or the image of an image collection tile
+type TiledTile struct {
+	ID          int             `xml:"id,attr" json:"id"`
+	Image       TiledImage      `xml:"image" json:"-"`
+	ImageSource string          `xml:"-" json:"image"`
+	Properties  []TiledProperty `xml:"properties>property" json:"properties"`
+}
+
+// TiledImage represents an image reference in a tileset
+type TiledImage struct {
+	Source string `xml:"source,attr"`
+}
+
+// TiledLayer represents a tile layer, object layer or group layer
+type TiledLayer struct {
+	XMLName    xml.Name        `json:"-"`
+	Type       string          `xml:"-" json:"type"`
+	Name       string          `xml:"name,attr" json:"name"`
+	Width      int             `xml:"width,attr" json:"width"`
+	Height     int             `xml:"height,attr" json:"height"`
+	Data       TiledData       `xml:"data" json:"data"`
+	Objects    []TiledObject   `xml:"object" json:"objects"`
+	Layers     []TiledLayer    `xml:",any" json:"layers"`
+	Properties []TiledProperty `xml:"properties>property" json:"properties"`
+
+	Encoding    string `xml:"-" json:"encoding"`
+	Compression string `xml:"-" json:"compression"`
+}
+
+// TiledData represents the tile ids of a tile layer, either as csv or base64 text or as a json array
+type TiledData struct {
+	Encoding    string `xml:"encoding,attr"`
+	Compression string `xml:"compression,attr"`
+	Text        string `xml:",chardata"`
+	Tiles       []struct {
+		GID uint32 `xml:"gid,attr"`
+	} `xml:"tile"`
+	GIDs []uint32
+}
+
+// TiledObject represents an object placed on an object layer
+type TiledObject struct {
+	ID         int             `xml:"id,attr" json:"id"`
+	Name       string          `xml:"name,attr" json:"name"`
+	Type       string          `xml:"type,attr" json:"type"`
+	Class      string          `xml:"class,attr" json:"class"`
+	GID        uint32          `xml:"gid,attr" json:"gid"`
+	X          float64         `xml:"x,attr" json:"x"`
+	Y          float64         `xml:"y,attr" json:"y"`
+	Width      float64         `xml:"width,attr" json:"width"`
+	Height     float64         `xml:"height,attr" json:"height"`
+	Properties []TiledProperty `xml:"properties>property" json:"properties"`
+}
+
+// TiledProperty represents a custom property. Values of every type are kept as their string form.
+type TiledProperty struct {
+	Name  string     `xml:"name,attr" json:"name"`
+	Type  string     `xml:"type,attr" json:"type"`
+	Value TiledValue `xml:"value,attr" json:"value"`
+}
+
+// TiledValue is a property value that may be a string, number or bool in json
+type TiledValue string
+
+func (v *TiledValue) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*v = TiledValue(s)
+		return nil
+	}
+	*v = TiledValue(data)
+	return nil
+}
+
+func (d *TiledData) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &d.Text)
+	}
+	return json.Unmarshal(data, &d.GIDs)
+}
+
+// LoadTiledMap reads a Tiled .tmx or .tmj map into a level.
+// Tile layers become tiles, with the "collider" tile or layer property setting Tile.Collider.
+// Objects become doodads, characters or enemies by their type (or class), an object of type "spawn" places the player,
+// and rectangles of type "checkpoint" are checkpoints.
+// The "camera", "respawnEnemies", "respawnHealth" and "music" map properties are read like the fields of a json level.
+// Tileset tiles that are not already in sprites are loaded from their images and added to it.
+func LoadTiledMap(path string, sprites map[string]Sprite, dialogueGraphs map[string]*DialogueGraph) (*Level, error) {
+	var tiledMap TiledMap
+	if err := ReadTiledFile(path, &tiledMap); err != nil {
+		return nil, err
+	}
+	if tiledMap.Orientation != "" && tiledMap.Orientation != "orthogonal" {
+		return nil, fmt.Errorf("%s: orientation: unsupported orientation %q", path, tiledMap.Orientation)
+	}
+	if tiledMap.Infinite {
+		return nil, fmt.Errorf("%s: infinite: infinite maps are not supported", path)
+	}
+
+	dir := filepath.Dir(path)
+	tileSprites := map[uint32]Sprite{}
+	tileProperties := map[uint32]map[string]string{}
+	for i := range tiledMap.Tilesets {
+		tileset := &tiledMap.Tilesets[i]
+		if err := tileset.Resolve(dir); err != nil {
+			return nil, fmt.Errorf("%s: tilesets[%d]: %w", path, i, err)
+		}
+		if err := tileset.Sprites(sprites, tileSprites, tileProperties); err != nil {
+			return nil, fmt.Errorf("%s: tileset %q: %w", path, tileset.Name, err)
+		}
+	}
+
+	level := Level{
+		Name:   strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		Path:   path,
+		Bounds: image.Rect(0, 0, tiledMap.Width*tiledMap.TileWidth, tiledMap.Height*tiledMap.TileHeight),
+	}
+	mapProperties := TiledProperties(tiledMap.Properties)
+	camera, err := ParseCameraMode(mapProperties["camera"])
+	if err != nil {
+		return nil, fmt.Errorf("%s: camera: %w", path, err)
+	}
+	level.Camera = camera
+	if v, ok := mapProperties["respawnEnemies"]; ok {
+		if level.RespawnEnemies, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("%s: respawnEnemies: must be a bool, got %q", path, v)
+		}
+	}
+	if v, ok := mapProperties["respawnHealth"]; ok {
+		if level.RespawnHealth, err = strconv.Atoi(v); err != nil || level.RespawnHealth < 0 {
+			return nil, fmt.Errorf("%s: respawnHealth: must be a non-negative int, got %q", path, v)
+		}
+	}
+	level.Music = mapProperties["music"]
+	spawned := false
+	layerNum := 0
+
+	var load func(layers []TiledLayer) error
+	load = func(layers []TiledLayer) error {
+		for _, layer := range layers {
+			layerProperties := TiledProperties(layer.Properties)
+			switch layer.Kind() {
+			case "group":
+				if err := load(layer.Layers); err != nil {
+					return err
+				}
+			case "tilelayer":
+				gids, err := layer.GIDs()
+				if err != nil {
+					return fmt.Errorf("layer %q: data: %w", layer.Name, err)
+				}
+				if len(gids) != layer.Width*layer.Height {
+					return fmt.Errorf("layer %q: data: expected %d tiles, got %d", layer.Name, layer.Width*layer.Height, len(gids))
+				}
+				layerCollider := layerProperties["collider"] == "true"
+				for i, gid := range gids {
+					gid &= TiledGIDMask
+					if gid == 0 {
+						continue
+					}
+					sprite, ok := tileSprites[gid]
+					if !ok {
+						return fmt.Errorf("layer %q: data[%d]: unknown tile id %d", layer.Name, i, gid)
+					}
+					col, row := i%layer.Width, i/layer.Width
+					// Tiled aligns tiles larger than the grid to the bottom left of their cell
+					level.Tiles = append(level.Tiles, Tile{
+						X:        col*tiledMap.TileWidth + sprite.FrameWidth/2,
+						Y:        (row + 1) * tiledMap.TileHeight,
+						Sprite:   sprite,
+						Collider: layerCollider || tileProperties[gid]["collider"] == "true",
+						Layer:    layerNum,
+					})
+				}
+				layerNum++
+			case "objectgroup":
+				for _, object := range layer.Objects {
+					isSpawn, err := level.AddTiledObject(object, tileSprites, sprites, dialogueGraphs)
+					if err != nil {
+						return fmt.Errorf("layer %q: object %d: %w", layer.Name, object.ID, err)
+					}
+					spawned = spawned || isSpawn
+				}
+			}
+		}
+		return nil
+	}
+	if err := load(tiledMap.Layers); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !spawned {
+		return nil, fmt.Errorf("%s: missing an object of type \"spawn\"", path)
+	}
+
+	return &level, nil
+}
+
+// ReadTiledFile decodes a Tiled file as xml or json depending on its extension
+func ReadTiledFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	switch filepath.Ext(path) {
+	case ".tmx", ".tsx":
+		if err := xml.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		if err := json.Unmarshal(data, v); err != nil {
+			return JSONError(path, data, err)
+		}
+	}
+	return nil
+}
+
+// Resolve loads the tileset from its external source if it has one and normalizes the xml and json forms of its images
+func (t *TiledTileset) Resolve(dir string) error {
+	t.Dir = dir
+	if t.Source != "" {
+		firstGID := t.FirstGID
+		source := filepath.Join(dir, t.Source)
+		if err := ReadTiledFile(source, t); err != nil {
+			return err
+		}
+		t.FirstGID = firstGID
+		t.Dir = filepath.Dir(source)
+	}
+	if t.ImageSource != "" {
+		t.Image.Source = t.ImageSource
+	}
+	for i := range t.Tiles {
+		if t.Tiles[i].ImageSource != "" {
+			t.Tiles[i].Image.Source = t.Tiles[i].ImageSource
+		}
+	}
+	return nil
+}
+
+// Sprites fills tileSprites and tileProperties by global tile id.
+// Image collection tiles reuse the sprite keyed by the camelCased image file name, like sprites.json does.
+// Tiles cut from a tileset image are added to sprites keyed by the camelCased tileset name and the tile id.
+func (t *TiledTileset) Sprites(sprites map[string]Sprite, tileSprites map[uint32]Sprite, tileProperties map[uint32]map[string]string) error {
+	for _, tile := range t.Tiles {
+		gid := t.FirstGID + uint32(tile.ID)
+		tileProperties[gid] = TiledProperties(tile.Properties)
+		if tile.Image.Source == "" {
+			continue
+		}
+		base := filepath.Base(tile.Image.Source)
+		k := CamelCase(base[:len(base)-len(filepath.Ext(base))])
+		sprite, ok := sprites[k]
+		if !ok {
+			img, err := LoadImage(filepath.Join(t.Dir, tile.Image.Source))
+			if err != nil {
+				return fmt.Errorf("tiles[%d].image: %w", tile.ID, err)
+			}
+			sprite = Sprite{
+				Key:         k,
+				FrameWidth:  img.Bounds().Dx(),
+				FrameHeight: img.Bounds().Dy(),
+				FrameLen:    1,
+				Image:       img,
+			}
+			sprites[k] = sprite
+		}
+		tileSprites[gid] = sprite
+	}
+
+	if t.Image.Source == "" {
+		return nil
+	}
+	if t.Columns < 1 || t.TileWidth < 1 || t.TileHeight < 1 {
+		return errors.New("columns, tilewidth and tileheight must be set for a tileset image")
+	}
+	img, err := LoadImage(filepath.Join(t.Dir, t.Image.Source))
+	if err != nil {
+		return fmt.Errorf("image: %w", err)
+	}
+	prefix := CamelCase(strings.ReplaceAll(t.Name, " ", "_"))
+	for id := 0; id < t.TileCount; id++ {
+		x := t.Margin + (id%t.Columns)*(t.TileWidth+t.Spacing)
+		y := t.Margin + (id/t.Columns)*(t.TileHeight+t.Spacing)
+		sprite := Sprite{
+			Key:         prefix + strconv.Itoa(id),
+			FrameWidth:  t.TileWidth,
+			FrameHeight: t.TileHeight,
+			FrameLen:    1,
+			Image:       img.SubImage(image.Rect(x, y, x+t.TileWidth, y+t.TileHeight)).(Image),
+		}
+		sprites[sprite.Key] = sprite
+		tileSprites[t.FirstGID+uint32(id)] = sprite
+	}
+	return nil
+}
+
+// Kind returns the json layer type for both xml elements and json layers
+func (l *TiledLayer) Kind() string {
+	switch l.XMLName.Local {
+	case "layer":
+		return "tilelayer"
+	case "":
+		return l.Type
+	default:
+		return l.XMLName.Local
+	}
+}
+
+// GIDs decodes the global tile ids of a tile layer, row by row
+func (l *TiledLayer) GIDs() ([]uint32, error) {
+	data := l.Data
+	if data.GIDs != nil {
+		return data.GIDs, nil
+	}
+	if len(data.Tiles) > 0 {
+		gids := make([]uint32, len(data.Tiles))
+		for i, t := range data.Tiles {
+			gids[i] = t.GID
+		}
+		return gids, nil
+	}
+	encoding, compression := data.Encoding, data.Compression
+	if l.Encoding != "" {
+		encoding, compression = l.Encoding, l.Compression
+	}
+
+	switch encoding {
+	case "csv":
+		var gids []uint32
+		for _, v := range strings.Split(data.Text, ",") {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				continue
+			}
+			gid, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			gids = append(gids, uint32(gid))
+		}
+		return gids, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(data.Text))
+		if err != nil {
+			return nil, err
+		}
+		var r io.Reader = bytes.NewReader(raw)
+		switch compression {
+		case "":
+		case "zlib":
+			if r, err = zlib.NewReader(r); err != nil {
+				return nil, err
+			}
+		case "gzip":
+			if r, err = gzip.NewReader(r); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported compression %q", compression)
+		}
+		raw, err = io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		gids := make([]uint32, len(raw)/4)
+		for i := range gids {
+			gids[i] = binary.LittleEndian.Uint32(raw[i*4:])
+		}
+		return gids, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+// AddTiledObject adds the game element an object describes to the level. It returns true if the object was the spawn point.
+func (l *Level) AddTiledObject(o TiledObject, tileSprites map[uint32]Sprite, sprites map[string]Sprite, dialogueGraphs map[string]*DialogueGraph) (bool, error) {
+	properties := TiledProperties(o.Properties)
+	kind := o.Class
+	if kind == "" {
+		kind = o.Type
+	}
+	if kind == "" && o.GID != 0 {
+		kind = "doodad"
+	}
+
+	// Tile objects are positioned by their bottom left corner and other objects by their top left corner,
+	// where game elements are positioned by the center of their "feet"
+	x := int(o.X + o.Width/2)
+	y := int(o.Y)
+	if o.GID == 0 {
+		y = int(o.Y + o.Height)
+	}
+
+	if kind == "spawn" {
+		l.Spawn = image.Pt(x, y)
+		return true, nil
+	}
+	if kind == "checkpoint" {
+		if o.Width <= 0 || o.Height <= 0 {
+			return false, errors.New("checkpoint: must be a rectangle")
+		}
+		l.Checkpoints = append(l.Checkpoints, image.Rect(int(o.X), int(o.Y), int(o.X+o.Width), int(o.Y+o.Height)))
+		return false, nil
+	}
+	if kind == "" {
+		return false, nil
+	}
+
+	var sprite Sprite
+	if k, ok := properties["sprite"]; ok {
+		if sprite, ok = sprites[k]; !ok {
+			return false, fmt.Errorf("sprite: unknown sprite %q", k)
+		}
+	} else if o.GID != 0 {
+		var ok bool
+		if sprite, ok = tileSprites[o.GID&TiledGIDMask]; !ok {
+			return false, fmt.Errorf("gid: unknown tile id %d", o.GID&TiledGIDMask)
+		}
+	} else {
+		return false, errors.New("sprite: missing property or tile")
+	}
+
+	switch kind {
+	case "doodad":
+		l.Doodads = append(l.Doodads, Doodad{
+			X:      x,
+			Y:      y,
+			Sprite: sprite,
+		})
+	case "character":
+		k := properties["dialogueKey"]
+		if k == "" {
+			return false, errors.New("dialogueKey: missing property")
+		}
+		if _, ok := dialogueGraphs[k]; !ok {
+			return false, fmt.Errorf("dialogueKey: unknown dialogue %q", k)
+		}
+		l.Characters = append(l.Characters, Character{
+			X:              float64(x),
+			Y:              float64(y),
+			Sprite:         sprite,
+			DialogueGraphs: dialogueGraphs,
+			DialogueKey:    k,
+		})
+	case "enemy":
+		var pause int
+		if v, ok := properties["pause"]; ok {
+			var err error
+			if pause, err = strconv.Atoi(v); err != nil || pause < 0 {
+				return false, fmt.Errorf("pause: must be a non-negative int, got %q", v)
+			}
+		}
+		speed := float64(DefaultEnemySpeed)
+		if v, ok := properties["speed"]; ok {
+			var err error
+			if speed, err = strconv.ParseFloat(v, 64); err != nil || speed <= 0 {
+				return false, fmt.Errorf("speed: must be a positive number, got %q", v)
+			}
+		}
+		health := DefaultEnemyHealth
+		if v, ok := properties["health"]; ok {
+			var err error
+			if health, err = strconv.Atoi(v); err != nil || health <= 0 {
+				return false, fmt.Errorf("health: must be a positive int, got %q", v)
+			}
+		}
+		l.Enemies = append(l.Enemies, Enemy{
+			X:      float64(x),
+			Y:      float64(y),
+			Sprite: sprite,
+			Behavior: Behavior{
+				Pause: pause,
+			},
+			Type:   properties["enemyType"],
+			Health: health,
+			Motion: Motion{Speed: speed},
+		})
+	default:
+		return false, fmt.Errorf("type: unknown type %q", kind)
+	}
+	return false, nil
+}
+
+// TiledProperties converts a list of custom properties into a map by name
+func TiledProperties(properties []TiledProperty) map[string]string {
+	m := map[string]string{}
+	for _, p := range properties {
+		m[p.Name] = string(p.Value)
+	}
+	return m
+}
@@ -0,0 +1,175 @@
+package game
+
+import (
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadTiledField(t *testing.T) {
+	sprites, graphs := loadLevelAssets(t)
+	level, err := LoadLevel("../levels/field.tmj", sprites, graphs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if level.Name != "field" || level.Bounds != image.Rect(0, 0, 320, 320) || level.Music != "field" {
+		t.Errorf("expected the 320x320 field with field music, got %q %v %q", level.Name, level.Bounds, level.Music)
+	}
+	if len(level.Characters) != 1 || level.Characters[0].DialogueKey != "elder" || level.Characters[0].Sprite.Key != "elderStandSouth" {
+		t.Errorf("expected the elder, got %+v", level.Characters)
+	}
+	if len(level.Enemies) != 1 || level.Enemies[0].Type != "skeletonWizard" || level.Enemies[0].Behavior.Pause != 60 {
+		t.Errorf("expected a skeleton wizard pausing 60 frames, got %+v", level.Enemies)
+	}
+	if len(level.Doodads) != 2 || level.Doodads[0].Sprite.Key != "tree" {
+		t.Errorf("expected 2 trees, got %+v", level.Doodads)
+	}
+	if !level.Spawn.In(level.Bounds) {
+		t.Errorf("expected the spawn inside the level, got %v", level.Spawn)
+	}
+
+	// Stumps collide because of their tile property, and grass doesn't
+	var stumps int
+	for _, tile := range level.Tiles {
+		if tile.Collider != (tile.Sprite.Key == "stump") {
+			t.Fatalf("expected only stumps to collide, got %s with collider %v", tile.Sprite.Key, tile.Collider)
+		}
+		if tile.Collider {
+			stumps++
+		}
+	}
+	if stumps == 0 || len(level.Tiles) < 400 {
+		t.Errorf("expected grass everywhere and some stumps, got %d tiles and %d stumps", len(level.Tiles), stumps)
+	}
+}
+
+func TestLoadTiledTMX(t *testing.T) {
+	sprites, graphs := loadLevelAssets(t)
+	level, err := LoadLevel("testdata/tiled/small.tmx", sprites, graphs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if level.Bounds != image.Rect(0, 0, 48, 32) || level.Music != "field" || level.RespawnHealth != 40 {
+		t.Errorf("expected the map properties to be read, got %v %q %d", level.Bounds, level.Music, level.RespawnHealth)
+	}
+	if level.Camera != CameraRoom || !level.RespawnEnemies {
+		t.Errorf("expected a room camera that respawns enemies, got camera %v and respawn %v", level.Camera, level.RespawnEnemies)
+	}
+
+	// The zlib layer is cut from the image tileset, with its margin and spacing
+	if len(level.Tiles) != 8 {
+		t.Fatalf("expected 6 ground tiles, a wall and a flower, got %d tiles", len(level.Tiles))
+	}
+	for i, tile := range level.Tiles[:6] {
+		key, c := "tiles0", color.RGBA{R: 0xff, A: 0xff}
+		if i%2 == 1 {
+			key, c = "tiles1", color.RGBA{B: 0xff, A: 0xff}
+		}
+		x, y := i%3*16+8, (i/3+1)*16
+		if tile.Sprite.Key != key || tile.X != x || tile.Y != y || tile.Layer != 0 || tile.Collider {
+			t.Errorf("ground %d: expected %s at (%d,%d), got %s at (%d,%d) on layer %d", i, key, x, y, tile.Sprite.Key, tile.X, tile.Y, tile.Layer)
+		}
+		img := tile.Sprite.Image
+		if got := color.RGBAModel.Convert(img.At(img.Bounds().Min.X, img.Bounds().Min.Y)); got != c || img.Bounds().Dx() != 16 {
+			t.Errorf("ground %d: expected a 16px %v tile, got %v %v", i, c, got, img.Bounds())
+		}
+	}
+	if _, ok := sprites["tiles1"]; !ok {
+		t.Error("expected the tileset tiles to be added to the sprites")
+	}
+
+	// The gzip layer collides because of its layer property, and the flipped csv tile in the group has its flags stripped
+	wall, flower := level.Tiles[6], level.Tiles[7]
+	if wall.Sprite.Key != "stump" || !wall.Collider || wall.X != 32+wall.Sprite.FrameWidth/2 || wall.Y != 16 || wall.Layer != 1 {
+		t.Errorf("expected a colliding stump at the top right, got %+v", wall)
+	}
+	if flower.Sprite.Key != "grass" || flower.Collider || flower.X != 16+flower.Sprite.FrameWidth/2 || flower.Layer != 2 {
+		t.Errorf("expected grass in the top middle on the group layer, got %+v", flower)
+	}
+
+	if level.Spawn != image.Pt(8, 8) {
+		t.Errorf("expected the spawn at (8,8), got %v", level.Spawn)
+	}
+	if len(level.Checkpoints) != 1 || level.Checkpoints[0] != image.Rect(16, 0, 32, 16) {
+		t.Errorf("expected a checkpoint, got %v", level.Checkpoints)
+	}
+	if len(level.Doodads) != 1 || level.Doodads[0].Sprite.Key != "stump" || level.Doodads[0].X != 48 || level.Doodads[0].Y != 32 {
+		t.Errorf("expected a stump doodad by its bottom left corner, got %+v", level.Doodads)
+	}
+	if len(level.Characters) != 1 || level.Characters[0].X != 8 || level.Characters[0].Y != 16 || level.Characters[0].DialogueKey != "elder" {
+		t.Errorf("expected the elder from its class, got %+v", level.Characters)
+	}
+	if len(level.Enemies) != 1 {
+		t.Fatalf("expected an enemy, got %+v", level.Enemies)
+	}
+	if e := level.Enemies[0]; e.X != 24 || e.Y != 32 || e.Health != 2 || e.Motion.Speed != 0.5 || e.Behavior.Pause != 30 || e.Type != "skeletonWizard" {
+		t.Errorf("expected the enemy properties to be read, got %+v", e)
+	}
+}
+
+func TestTiledErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string // The file of the fixture to change
+		old     string
+		new     string
+		wantErr string
+	}{
+		{"bad encoding", "small.tmx", `encoding="csv"`, `encoding="base32"`, `layer "flowers": data: unsupported encoding "base32"`},
+		{"bad compression", "small.tmx", `compression="gzip"`, `compression="lzma"`, `layer "walls": data: unsupported compression "lzma"`},
+		{"wrong tile count", "small.tmx", "0,0,0\n", "0,0\n", `layer "flowers": data: expected 6 tiles, got 5`},
+		{"unknown tile", "small.tmx", "2147483652", "9", `layer "flowers": data[1]: unknown tile id 9`},
+		{"unknown object type", "small.tmx", `type="enemy"`, `type="dragon"`, `layer "entities": object 5: type: unknown type "dragon"`},
+		{"unknown dialogue", "small.tmx", `value="elder"`, `value="wizard"`, `object 4: dialogueKey: unknown dialogue "wizard"`},
+		{"round checkpoint", "small.tmx", `width="16" height="16"/>`, `/>`, `object 2: checkpoint: must be a rectangle`},
+		{"missing spawn", "small.tmx", `type="spawn"`, `type=""`, `missing an object of type "spawn"`},
+		{"missing tileset", "small.tmx", `source="props.tsj"`, `source="missing.tsj"`, `tilesets[1]: open`},
+		{"missing tileset image", "tiles.tsx", `source="tiles.png"`, `source="missing.png"`, `tileset "tiles": image: open`},
+		{"bad respawn health", "small.tmx", `value="40"`, `value="-1"`, `respawnHealth: must be a non-negative int`},
+		{"unknown camera", "small.tmx", `value="room"`, `value="orbit"`, `camera: unknown camera mode "orbit"`},
+		{"bad respawn enemies", "small.tmx", `type="bool" value="true"`, `type="bool" value="often"`, `respawnEnemies: must be a bool, got "often"`},
+	}
+	sprites, graphs := loadLevelAssets(t)
+	for _, tt := range tests {
+		dir := t.TempDir()
+		entries, err := os.ReadDir("testdata/tiled")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			data, err := os.ReadFile(filepath.Join("testdata/tiled", e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Name() == tt.file {
+				if !strings.Contains(string(data), tt.old) {
+					t.Fatalf("%s: %s doesn't contain %q", tt.name, tt.file, tt.old)
+				}
+				data = []byte(strings.Replace(string(data), tt.old, tt.new, 1))
+			}
+			// The collection tileset refers to sprites relative to the fixture directory
+			if e.Name() == "props.tsj" {
+				abs, err := filepath.Abs("../sprites")
+				if err != nil {
+					t.Fatal(err)
+				}
+				data = []byte(strings.ReplaceAll(string(data), "../../../sprites", filepath.ToSlash(abs)))
+			}
+			if err := os.WriteFile(filepath.Join(dir, e.Name()), data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		_, err = LoadLevel(filepath.Join(dir, "small.tmx"), sprites, graphs)
+		if err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		} else if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: expected an error containing %q, got %q", tt.name, tt.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+package game
+
+import "image"
+
+func UpdateInteraction(g *Game) {
+	if g.InteractionTarget != nil {
+		// Render the next rune to scroll the text
+		g.InteractionTarget.AdvanceRune()
+		if g.Input.IsJustReleased(MenuLeft) {
+			g.InteractionTarget.SelectOption(-1)
+		} else if g.Input.IsJustReleased(MenuRight) {
+			g.InteractionTarget.SelectOption(1)
+		} else if g.Input.IsJustReleased(Confirm) {
+			g.PlaySound(DialogueSound)
+			// If out of dialogue, end the interaction
+			if g.InteractionTarget.IsExhausted() {
+				g.InteractionTarget.AdvancePhrase()
+				g.InteractionTarget = nil
+			} else {
+				g.InteractionTarget.AdvancePhrase()
+			}
+		}
+	} else if !g.Player.Animation && g.Input.IsJustReleased(Interact) {
+		for i := range g.Characters {
+			c := &g.Characters[i]
+			playerRect := g.Player.Hitbox(0, 0)
+			characterRect := c.Hitbox(0, 0)
+			// Check if a side of the player rect is touching the character rect and the midpoint of that side is touching the character rect
+			if AbsDiff(playerRect.Max.X, characterRect.Min.X) <= 1 && playerRect.Min.Y+(playerRect.Dy()/2) >= characterRect.Min.Y && playerRect.Min.Y+(playerRect.Dy()/2) <= characterRect.Max.Y {
+				g.InteractionTarget = c
+				g.Player.FrameNum = 0
+				g.Player.FrameDur = 0
+				g.Player.Sprite = g.Sprites["linkStandEast"]
+			} else if AbsDiff(playerRect.Max.Y, characterRect.Min.Y) <= 1 && playerRect.Min.X+(playerRect.Dx()/2) >= characterRect.Min.X && playerRect.Min.X+(playerRect.Dx()/2) <= characterRect.Max.X {
+				g.InteractionTarget = c
+				g.Player.FrameNum = 0
+				g.Player.FrameDur = 0
+				g.Player.Sprite = g.Sprites["linkStandSouth"]
+			} else if AbsDiff(playerRect.Min.X, characterRect.Max.X) <= 1 && playerRect.Min.Y+(playerRect.Dy()/2) >= characterRect.Min.Y && playerRect.Min.Y+(playerRect.Dy()/2) <= characterRect.Max.Y {
+				g.InteractionTarget = c
+				g.Player.FrameNum = 0
+				g.Player.FrameDur = 0
+				g.Player.Sprite = g.Sprites["linkStandWest"]
+			} else if AbsDiff(playerRect.Min.Y, characterRect.Max.Y) <= 1 && playerRect.Min.X+(playerRect.Dx()/2) >= characterRect.Min.X && playerRect.Min.X+(playerRect.Dx()/2) <= characterRect.Max.X {
+				g.InteractionTarget = c
+				g.Player.FrameNum = 0
+				g.Player.FrameDur = 0
+				g.Player.Sprite = g.Sprites["linkStandNorth"]
+			}
+		}
+		if g.InteractionTarget != nil {
+			g.InteractionTarget.StartDialogue()
+		}
+	}
+
+}
+
+func UpdatePlayer(g *Game) {
+	animEnd := false
+
+	if g.Player.Invulnerable > 0 {
+		g.Player.Invulnerable--
+	}
+
+	if g.Player.Sprite.FrameLen > 1 {
+		g.Player.FrameDur++
+		// Use >= because the 0 frame counts as one
+		if g.Player.FrameDur >= g.Player.Sprite.FrameDur {
+			g.Player.FrameDur = 0
+			g.Player.FrameNum++
+			// FrameNum is zero indexed and FrameLen is a natural number, so subtract 1 for the mod operation
+			g.Player.FrameNum = g.Player.FrameNum % (g.Player.Sprite.FrameLen - 1)
+			// End the animation if the last render was the last frame
+			if g.Player.Animation && g.Player.FrameNum == 0 {
+				g.Player.Weapon.IsAttacking = false
+				g.Player.Animation = false
+				animEnd = true
+			}
+		}
+	}
+
+	// The player has no control while knocked back, and slides along walls like walking
+	if g.Player.Knockback > 0 {
+		g.Player.Knockback--
+		MovePlayer(g, g.Player.VX, g.Player.VY)
+		if g.Player.Knockback == 0 {
+			g.Player.VX, g.Player.VY = 0, 0
+		}
+		g.Colliders.Update(&g.Player)
+		return
+	}
+
+	// Every direction held down adds to the move, so diagonals can slide along walls
+	dx, dy := 0, 0
+
+	if g.Input.IsPressed(MoveLeft) {
+		// Start the walk left animation if the player just pressed left or if an animation ended and the player was already moving left
+		if g.Input.IsJustPressed(MoveLeft) || (IsOtherDirectionJustReleased(g.Input, MoveLeft) && IsLeastKeyPressDuration(g.Input, MoveLeft)) || animEnd {
+			g.Player.LastDir = West
+			g.Player.FrameNum = 0
+			g.Player.FrameDur = 0
+			g.Player.Sprite = g.Sprites["linkWalkWest"]
+		}
+		dx--
+	}
+
+	if g.Input.IsPressed(MoveRight) {
+		// Start the walk right animation if the player just pressed right or if an animation ended and the player was already moving right
+		if g.Input.IsJustPressed(MoveRight) || (IsOtherDirectionJustReleased(g.Input, MoveRight) && IsLeastKeyPressDuration(g.Input, MoveRight)) || animEnd {
+			g.Player.LastDir = East
+			g.Player.FrameNum = 0
+			g.Player.FrameDur = 0
+			g.Player.Sprite = g.Sprites["linkWalkEast"]
+		}
+		dx++
+	}
+
+	if g.Input.IsPressed(MoveUp) {
+		// Start the walk up animation if the player just pressed up or if an animation ended and the player was already moving up
+		if g.Input.IsJustPressed(MoveUp) || (IsOtherDirectionJustReleased(g.Input, MoveUp) && IsLeastKeyPressDuration(g.Input, MoveUp)) || animEnd {
+			g.Player.LastDir = North
+			g.Player.FrameNum = 0
+			g.Player.FrameDur = 0
+			g.Player.Sprite = g.Sprites["linkWalkNorth"]
+		}
+		dy--
+	}
+
+	if g.Input.IsPressed(MoveDown) {
+		// Start the walk down animation if the player just pressed down or if an animation ended and the player was already moving down
+		if g.Input.IsJustPressed(MoveDown) || (IsOtherDirectionJustReleased(g.Input, MoveDown) && IsLeastKeyPressDuration(g.Input, MoveDown)) || animEnd {
+			g.Player.LastDir = South
+			g.Player.FrameNum = 0
+			g.Player.FrameDur = 0
+			g.Player.Sprite = g.Sprites["linkWalkSouth"]
+		}
+		dy++
+	}
+
+	// Diagonals are normalized by Steer, so walking diagonally isn't faster
+	g.Player.Steer(float64(dx), float64(dy))
+	MovePlayer(g, g.Player.VX, g.Player.VY)
+
+	// If no direction is pressed and the player is not in an animation, select a standing sprite based on the last direction the player moved
+	if !g.Input.IsPressed(MoveLeft) && !g.Input.IsPressed(MoveRight) && !g.Input.IsPressed(MoveUp) && !g.Input.IsPressed(MoveDown) && !g.Player.Animation {
+		g.Player.FrameNum = 0
+		g.Player.FrameDur = 0
+		if g.Player.LastDir == West {
+			g.Player.Sprite = g.Sprites["linkStandWest"]
+		} else if g.Player.LastDir == East {
+			g.Player.Sprite = g.Sprites["linkStandEast"]
+		} else if g.Player.LastDir == North {
+			g.Player.Sprite = g.Sprites["linkStandNorth"]
+		} else if g.Player.LastDir == South {
+			g.Player.Sprite = g.Sprites["linkStandSouth"]
+		}
+	}
+
+	// If starting an animation
+	if !g.Player.Animation && g.Input.IsPressed(Attack) {
+		g.Player.Animation = true
+		g.Player.FrameNum = 0
+		g.Player.FrameDur = 0
+		g.Player.Weapon.Wielder = &g.Player
+		g.Player.Weapon.IsAttacking = true
+		g.Player.Weapon.Dir = g.Player.LastDir
+		g.Player.Weapon.Swing++
+		g.PlaySound(SwordSound)
+		if g.Player.LastDir == West {
+			g.Player.Sprite = g.Sprites["linkAttackWest"]
+		} else if g.Player.LastDir == East {
+			g.Player.Sprite = g.Sprites["linkAttackEast"]
+		} else if g.Player.LastDir == North {
+			g.Player.Sprite = g.Sprites["linkAttackNorth"]
+		} else if g.Player.LastDir == South {
+			g.Player.Sprite = g.Sprites["linkAttackSouth"]
+		}
+	}
+
+	// Sprites are different sizes, so the hitbox can change without moving
+	g.Colliders.Update(&g.Player)
+}
+
+// MovePlayer moves the player with Move. Walking into an enemy counts as touching it.
+func MovePlayer(g *Game, dx, dy float64) {
+	for _, c := range Move(g, &g.Player, dx, dy) {
+		if e, ok := c.(*Enemy); ok {
+			g.EnemyCollision = e
+		}
+	}
+}
+
+// UpdateCamera centers the camera on the middle of the player sprite, or in room mode scrolls to the room the player walked into
+func UpdateCamera(g *Game) {
+	x, y := Pixel(g.Player.X), Pixel(g.Player.Y)-g.Player.Sprite.FrameHeight/2
+	if g.Camera.Mode != CameraRoom {
+		g.Camera.Follow(x, y)
+		return
+	}
+	if g.Camera.IsScrolling() {
+		g.Camera.AdvanceScroll()
+		return
+	}
+	if room := g.Camera.RoomAt(x, y); room != g.Camera.Room {
+		LeaveRoom(g, g.Camera.Room)
+		EnterRoom(g, room)
+		g.Camera.ScrollTo(room)
+	}
+}
+
+// ResetCamera moves the camera straight to the player without scrolling, for when the player is placed somewhere new
+func ResetCamera(g *Game) {
+	if g.Camera.Mode == CameraRoom {
+		g.Camera.ShowRoom(g.Camera.RoomAt(Pixel(g.Player.X), Pixel(g.Player.Y)-g.Player.Sprite.FrameHeight/2))
+	} else {
+		UpdateCamera(g)
+	}
+}
+
+func UpdateCharacters(g *Game) {
+
+}
+
+func UpdateEnemies(g *Game) {
+	for i := 0; i < len(g.Enemies); i++ {
+		if g.Enemies[i].Dying {
+			AdvanceDeath(&g.Enemies[i])
+			continue
+		}
+		// Enemies outside of the current room are suspended
+		if !g.Camera.IsActive(Pixel(g.Enemies[i].X), Pixel(g.Enemies[i].Y)) {
+			continue
+		}
+		if UpdateEnemyHurt(g, &g.Enemies[i]) {
+			g.Colliders.Update(&g.Enemies[i])
+			continue
+		}
+		AdvanceBehavior(g, &g.Enemies[i])
+		g.Colliders.Update(&g.Enemies[i])
+	}
+}
+
+func UpdateProjectiles(g *Game) {
+	for i := range g.Projectiles {
+		p := &g.Projectiles[i]
+		// Projectiles outside of the current room are suspended, like enemies
+		if !g.Camera.IsActive(Pixel(p.X), Pixel(p.Y)) {
+			continue
+		}
+		p.Steer(p.Dir.Vector())
+		hits := Move(g, p, p.VX, p.VY)
+		// A projectile that leaves the level can't hit anything anymore
+		if !image.Pt(Pixel(p.X), Pixel(p.Y)).In(g.Level.Bounds) {
+			p.Spent = true
+			continue
+		}
+		// A projectile that hits the player is spent when the damage is dealt, anything else it hits just stops it
+		for _, c := range hits {
+			if _, ok := c.(*Player); ok {
+				g.ProjectileCollision = p
+			}
+		}
+		if len(hits) > 0 && g.ProjectileCollision != p {
+			p.Spent = true
+		}
+	}
+}
+
+// UpdatePickups collects the pickups the player is standing on
+func UpdatePickups(g *Game) {
+	player := g.Player.Hitbox(0, 0)
+	for i := range g.Pickups {
+		if !g.Pickups[i].Collected && player.Overlaps(g.Pickups[i].Hitbox(0, 0)) {
+			Collect(g, &g.Pickups[i])
+		}
+	}
+}
+
+func UpdateDamage(g *Game) {
+	// A hit makes the player invulnerable for a while, so touching an enemy doesn't drain health every tick
+	var hit bool
+	if p := g.ProjectileCollision; p != nil {
+		hit = HitPlayer(g, PlayerHitDamage, p.X, p.Y)
+		p.Spent = true
+	} else if e := g.EnemyCollision; e != nil {
+		hit = HitPlayer(g, PlayerHitDamage, e.X, e.Y)
+	}
+	if hit {
+		g.PlaySound(HitSound)
+	}
+	g.ProjectileCollision = nil
+	g.EnemyCollision = nil
+	if g.Player.Health <= 0 {
+		KillPlayer(g)
+		return
+	}
+
+	// The player's weapon hits every enemy its hitbox overlaps in this frame of the swing, but each enemy only once per swing
+	if w := g.Player.Weapon; w != nil && w.IsAttacking {
+		for _, c := range g.Colliders.Query(w.Hitbox(0, 0)) {
+			if e, ok := c.(*Enemy); ok && e.LastSwing != w.Swing {
+				e.LastSwing = w.Swing
+				if HitEnemy(g, e, w.Damage, g.Player.X, g.Player.Y) && e.Health <= 0 {
+					Kill(g, e)
+				}
+			}
+		}
+	}
+}
+
+// UpdateEnemyHurt counts down how long ago an enemy was hit and moves it while it is knocked back.
+// It returns true if the enemy is knocked back, which interrupts its behavior.
+func UpdateEnemyHurt(g *Game, e *Enemy) bool {
+	if e.Invulnerable > 0 {
+		e.Invulnerable--
+	}
+	if e.Knockback == 0 {
+		return false
+	}
+	e.Knockback--
+	MoveEnemy(g, e, e.VX, e.VY)
+	if e.Knockback == 0 {
+		e.VX, e.VY = 0, 0
+	}
+	return true
+}
+
+// Kill starts the death animation of an enemy and drops its loot. It stops blocking movement and fighting straight away.
+// The variable named after its type, like skeletonWizardDefeated, counts how many of the type were killed.
+func Kill(g *Game, e *Enemy) {
+	e.Dying = true
+	e.Hurt = Hurt{}
+	e.Sprite = g.Sprites["enemyDeath"]
+	e.FrameNum = 0
+	e.FrameDur = 0
+	e.VX, e.VY = 0, 0
+	g.Colliders.Remove(e)
+	DropLoot(g, e)
+	if e.Type != "" && g.Variables != nil {
+		g.Variables.Add(e.Type+"Defeated", 1)
+	}
+}
+
+// AdvanceDeath plays the next tick of an enemy's death animation. The frame number passes the last frame when it is over.
+func AdvanceDeath(e *Enemy) {
+	e.FrameDur++
+	if e.FrameDur >= e.Sprite.FrameDur {
+		e.FrameDur = 0
+		e.FrameNum++
+	}
+}
+
+// UpdateDespawns removes the enemies whose death animation is over, spent projectiles and collected pickups.
+// Removing is left until everything else has updated, so pointers into the slices stay valid during the update.
+func UpdateDespawns(g *Game) {
+	enemies := len(g.Enemies)
+	g.Enemies = Remove(g.Enemies, func(e *Enemy) bool { return e.Dying && e.FrameNum >= e.Sprite.FrameLen })
+	g.Projectiles = Remove(g.Projectiles, func(p *Projectile) bool { return p.Spent })
+	g.Pickups = Remove(g.Pickups, func(p *Pickup) bool { return p.Collected })
+	// The spatial hash points into the enemy slice, which was shifted
+	if len(g.Enemies) < enemies {
+		g.IndexColliders()
+	}
+}
@@ -0,0 +1,173 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func Contains(a []string, s string) bool {
+	for _, v := range a {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove removes the elements of a slice that remove returns true for, in place and keeping the order of the rest.
+// Pointers into the slice point at different elements afterwards, so anything holding them, like the spatial hash, has to be rebuilt.
+func Remove[T any](s []T, remove func(*T) bool) []T {
+	kept := s[:0]
+	for i := range s {
+		if !remove(&s[i]) {
+			kept = append(kept, s[i])
+		}
+	}
+	// Clear the leftover tail so it doesn't hold on to sprites
+	var zero T
+	for i := len(kept); i < len(s); i++ {
+		s[i] = zero
+	}
+	return kept
+}
+
+func Min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func Max(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
+
+// FloorDiv divides x by y rounding towards negative infinity
+func FloorDiv(x, y int) int {
+	if (x < 0) != (y < 0) && x%y != 0 {
+		return x/y - 1
+	}
+	return x / y
+}
+
+func Abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+// Sign returns -1, 0 or 1 for negative, zero and positive numbers
+func Sign(x int) int {
+	if x < 0 {
+		return -1
+	} else if x > 0 {
+		return 1
+	}
+	return 0
+}
+
+func AbsDiff(x, y int) int {
+	if x < y {
+		return y - x
+	}
+	return x - y
+}
+
+func CamelCase(s string) string {
+	var snek bool
+	var camel string
+	for i := 0; i < len(s); i++ {
+		char := string(s[i])
+		if char == "_" {
+			snek = true
+			continue
+		}
+		if snek {
+			snek = false
+			camel += strings.ToUpper(char)
+		} else {
+			camel += char
+		}
+	}
+	return camel
+}
+
+// Direction represents one of the four directions something can face or travel in. The zero direction faces the screen.
+type Direction int
+
+const (
+	South Direction = iota
+	North
+	West
+	East
+)
+
+// String returns the compass name of a direction, as used in sprite names
+func (d Direction) String() string {
+	switch d {
+	case North:
+		return "north"
+	case West:
+		return "west"
+	case East:
+		return "east"
+	default:
+		return "south"
+	}
+}
+
+// ParseDirection converts a compass name back into a direction
+func ParseDirection(s string) (Direction, error) {
+	switch s {
+	case "south", "":
+		return South, nil
+	case "north":
+		return North, nil
+	case "west":
+		return West, nil
+	case "east":
+		return East, nil
+	default:
+		return South, fmt.Errorf("unknown direction %q", s)
+	}
+}
+
+func (c *Character) Equal(v *Character) bool {
+	return c.X == v.X && c.Y == v.Y && c.Sprite.Image == v.Sprite.Image
+}
+
+func (e *Enemy) Equal(v *Enemy) bool {
+	return e.X == v.X && e.Y == v.Y && e.Sprite.Image == v.Sprite.Image
+}
+
+// JSONError prefixes a json decoding error with the file and the line and column it occurred at
+func JSONError(path string, data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	} else {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	line, col := LineCol(data, offset)
+	return fmt.Errorf("%s:%d:%d: %w", path, line, col, err)
+}
+
+// LineCol converts a byte offset into a 1-indexed line and column
+func LineCol(data []byte, offset int64) (int, int) {
+	offset = int64(Min(int(offset), len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
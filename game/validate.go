@@ -0,0 +1,226 @@
+package game
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// DialogueProblem is a mistake found in a dialogue file, at the position of the json value it is about
+type DialogueProblem struct {
+	Path    string // The dialogue file
+	Line    int
+	Col     int
+	Field   string // The json path of the value, like elder[2].connections[0]
+	Message string
+}
+
+func (p DialogueProblem) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", p.Path, p.Line, p.Col, p.Field, p.Message)
+}
+
+// ValidateDialogue checks a dialogue file for mistakes that would leave the player stuck in a dialogue:
+// duplicate ids, connections and options that lead to unknown nodes, options that lead outside the connections of their
+// node, nodes that can't be reached from the root and nodes with no way out that don't end the interaction.
+// It returns every problem found, sorted by position. The error is only for files that can't be read as dialogue.
+func ValidateDialogue(path string) ([]DialogueProblem, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rawDialogues map[string][]json.RawMessage
+	if err := json.Unmarshal(data, &rawDialogues); err != nil {
+		return nil, JSONError(path, data, err)
+	}
+	positions, err := JSONPositions(data)
+	if err != nil {
+		return nil, JSONError(path, data, err)
+	}
+
+	var problems []DialogueProblem
+	report := func(field, format string, args ...interface{}) {
+		line, col := LineCol(data, positions[field])
+		problems = append(problems, DialogueProblem{Path: path, Line: line, Col: col, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for k, rawNodes := range rawDialogues {
+		if len(rawNodes) == 0 {
+			report(k, "has no nodes")
+			continue
+		}
+		// Options and connections that can't be read are reported where they are and left out of the other checks
+		malformed := map[string]bool{}
+		nodes := make([]DialogueJSON, len(rawNodes))
+		for i, raw := range rawNodes {
+			field := fmt.Sprintf("%s[%d]", k, i)
+			for _, f := range decodeDialogueNode(raw, &nodes[i]) {
+				malformed[field+f.Field] = true
+				report(field+f.Field, "%s", f.Message)
+			}
+		}
+		ids := map[string]int{}
+		for i, node := range nodes {
+			field := fmt.Sprintf("%s[%d]", k, i)
+			if first, ok := ids[node.ID]; ok {
+				report(field+".id", "duplicate id %q, first used by %s[%d]", node.ID, k, first)
+			} else {
+				ids[node.ID] = i
+			}
+			if _, err := node.Node(); err != nil {
+				report(field, "%v", err)
+			}
+			for j, c := range node.Connections {
+				if _, err := ParseCondition(c.Condition); err != nil && !malformed[fmt.Sprintf("%s.connections[%d]", field, j)] {
+					report(fmt.Sprintf("%s.connections[%d]", field, j), "condition: %v", err)
+				}
+			}
+		}
+
+		for i, node := range nodes {
+			field := fmt.Sprintf("%s[%d]", k, i)
+			connections := map[string]bool{}
+			for j, c := range node.Connections {
+				if malformed[fmt.Sprintf("%s.connections[%d]", field, j)] {
+					continue
+				}
+				connections[c.Next] = true
+				if _, ok := ids[c.Next]; !ok {
+					report(fmt.Sprintf("%s.connections[%d]", field, j), "unknown node %q", c.Next)
+				}
+			}
+			for j, o := range node.Options {
+				if malformed[fmt.Sprintf("%s.options[%d]", field, j)] {
+					continue
+				}
+				if _, ok := ids[o.Next]; !ok {
+					report(fmt.Sprintf("%s.options[%d]", field, j), "unknown node %q", o.Next)
+				} else if !connections[o.Next] {
+					report(fmt.Sprintf("%s.options[%d]", field, j), "leads to %q, which isn't in the connections of the node", o.Next)
+				}
+			}
+			if len(node.Connections) == 0 && len(node.Options) == 0 && !node.End {
+				report(field, "node %q has no connections or options and doesn't end the interaction", node.ID)
+			}
+		}
+
+		// Walk from the root, which is the first node, through connections and options
+		reached := map[string]bool{nodes[0].ID: true}
+		queue := []string{nodes[0].ID}
+		for len(queue) > 0 {
+			node := nodes[ids[queue[0]]]
+			queue = queue[1:]
+			var next []string
+			for _, c := range node.Connections {
+				next = append(next, c.Next)
+			}
+			for _, o := range node.Options {
+				next = append(next, o.Next)
+			}
+			for _, id := range next {
+				if _, ok := ids[id]; ok && !reached[id] {
+					reached[id] = true
+					queue = append(queue, id)
+				}
+			}
+		}
+		for i, node := range nodes {
+			if !reached[node.ID] {
+				report(fmt.Sprintf("%s[%d]", k, i), "node %q can't be reached from the root %q", node.ID, nodes[0].ID)
+			}
+		}
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Col < problems[j].Col
+	})
+	return problems, nil
+}
+
+// decodeDialogueNode decodes a node of a dialogue file into node, reading each of its options and connections on
+// its own so one that is malformed doesn't hide the rest. It returns a problem for each value that couldn't be read,
+// with a field relative to the node like .options[1]. Values that couldn't be read are left empty.
+func decodeDialogueNode(data []byte, node *DialogueJSON) []DialogueProblem {
+	var raw struct {
+		DialogueJSON
+		Options     []json.RawMessage `json:"options"`
+		Connections []json.RawMessage `json:"connections"`
+	}
+	var problems []DialogueProblem
+	if err := json.Unmarshal(data, &raw); err != nil {
+		// Type errors are reported after the rest of the node is decoded
+		problems = append(problems, DialogueProblem{Message: err.Error()})
+	}
+	*node = raw.DialogueJSON
+	node.Options = make([]DialogueOptionJSON, len(raw.Options))
+	for i, o := range raw.Options {
+		if err := json.Unmarshal(o, &node.Options[i]); err != nil {
+			problems = append(problems, DialogueProblem{Field: fmt.Sprintf(".options[%d]", i), Message: err.Error()})
+		}
+	}
+	node.Connections = make([]DialogueConnectionJSON, len(raw.Connections))
+	for i, c := range raw.Connections {
+		if err := json.Unmarshal(c, &node.Connections[i]); err != nil {
+			problems = append(problems, DialogueProblem{Field: fmt.Sprintf(".connections[%d]", i), Message: err.Error()})
+		}
+	}
+	return problems
+}
+
+// JSONPositions returns the byte offset of every value in a json document by its path, like elder[2].connections[0].
+// The root value has the path "".
+func JSONPositions(data []byte) (map[string]int64, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	positions := map[string]int64{}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		// The decoder is past the previous token, so skip the space and separators before this value
+		start := dec.InputOffset()
+		for start < int64(len(data)) && isJSONSeparator(data[start]) {
+			start++
+		}
+		positions[path] = start
+
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				field := key.(string)
+				if path != "" {
+					field = path + "." + field
+				}
+				if err := walk(field); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				if err := walk(path + "[" + strconv.Itoa(i) + "]"); err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+		}
+		return err
+	}
+	return positions, walk("")
+}
+
+// isJSONSeparator returns true for the bytes between json values
+func isJSONSeparator(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n' || b == ',' || b == ':'
+}
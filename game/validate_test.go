@@ -0,0 +1,80 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDialogue(t *testing.T) {
+	problems, err := ValidateDialogue("../dialogue/dialogue.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("expected the game's dialogue to be valid, got %v", problems)
+	}
+
+	path := filepath.Join(t.TempDir(), "dialogue.json")
+	dialogue := `{
+    "a": [
+        {"id": "a0", "phrase": "hi", "connections": ["a1"], "options": [["ok", "a2"]]},
+        {"id": "a1", "phrase": "stuck"},
+        {"id": "a1", "phrase": "again", "end": true},
+        {"id": "a2", "phrase": "lost", "connections": ["a9"]},
+        {"id": "a3", "phrase": "alone", "end": true}
+    ]
+}`
+	if err := os.WriteFile(path, []byte(dialogue), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	problems, err = ValidateDialogue(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		":3:73: a[0].options[0]: leads to \"a2\", which isn't in the connections",
+		":4:9: a[1]: node \"a1\" has no connections or options",
+		":5:16: a[2].id: duplicate id \"a1\"",
+		":6:56: a[3].connections[0]: unknown node \"a9\"",
+		":7:9: a[4]: node \"a3\" can't be reached",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %v", len(want), problems)
+	}
+	for i, p := range problems {
+		if !strings.HasPrefix(p.Error(), path) || !strings.Contains(p.Error(), want[i]) {
+			t.Errorf("expected problem %d to contain %q, got %q", i, want[i], p.Error())
+		}
+	}
+}
+
+func TestValidateMalformedOptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dialogue.json")
+	dialogue := `{
+    "a": [
+        {"id": "a0", "phrase": "hi", "connections": ["a1", 7], "options": [["ok"], ["fine", "a1"]]},
+        {"id": "a1", "phrase": "bye", "end": true}
+    ]
+}`
+	if err := os.WriteFile(path, []byte(dialogue), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	problems, err := ValidateDialogue(path)
+	if err != nil {
+		t.Fatalf("expected malformed options to be reported as problems, got %v", err)
+	}
+	want := []string{
+		":3:60: a[0].connections[1]: ",
+		":3:76: a[0].options[0]: option must be a [text, next] pair, got 1 strings",
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("expected %d problems, got %v", len(want), problems)
+	}
+	for i, p := range problems {
+		if !strings.Contains(p.Error(), want[i]) {
+			t.Errorf("expected problem %d to contain %q, got %q", i, want[i], p.Error())
+		}
+	}
+}
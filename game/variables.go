@@ -0,0 +1,152 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Variables are named values that dialogue checks and sets, like whether the player answered a question.
+// Each value is a bool, an int or a string.
+type Variables map[string]interface{}
+
+// ParseValue converts a value decoded from json into a variable value. Whole numbers become ints.
+func ParseValue(v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case bool, int, string:
+		return v, nil
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("must be a whole number, got %v", v)
+		}
+		return int(v), nil
+	}
+	return nil, fmt.Errorf("must be a bool, int or string, got %v", v)
+}
+
+// ParseValues converts values decoded from json into variable values
+func ParseValues(values map[string]interface{}) (Variables, error) {
+	parsed := Variables{}
+	for k, v := range values {
+		value, err := ParseValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		parsed[k] = value
+	}
+	return parsed, nil
+}
+
+// Set copies values into the variables
+func (vars Variables) Set(values Variables) {
+	for k, v := range values {
+		vars[k] = v
+	}
+}
+
+// Add adds to an int variable, treating a missing variable as 0
+func (vars Variables) Add(key string, n int) {
+	i, _ := vars[key].(int)
+	vars[key] = i + n
+}
+
+// Condition is a check of a variable, like "answeredNo", "!answeredNo", "answer == no" or "skeletonWizardDefeated >= 2".
+// A variable on its own is true if it is true, a non-zero int or a non-empty string. A missing variable is false.
+// The zero condition always holds.
+type Condition struct {
+	Var   string
+	Op    string      // The comparison with the value, or "" to check the variable on its own
+	Not   bool        // Whether the check of the variable on its own is negated
+	Value interface{} // The value the variable is compared with
+}
+
+// conditionOps are the comparisons of conditions. Longer operators come first so "<=" isn't read as "<".
+var conditionOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// ParseCondition parses a condition, or returns the zero condition for ""
+func ParseCondition(s string) (Condition, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Condition{}, nil
+	}
+	for _, op := range conditionOps {
+		if i := strings.Index(s, op); i >= 0 {
+			c := Condition{Var: strings.TrimSpace(s[:i]), Op: op, Value: parseLiteral(strings.TrimSpace(s[i+len(op):]))}
+			if c.Var == "" {
+				return Condition{}, fmt.Errorf("missing variable before %s in %q", op, s)
+			}
+			if _, ok := c.Value.(int); !ok && op != "==" && op != "!=" {
+				return Condition{}, fmt.Errorf("%s must compare with an int in %q", op, s)
+			}
+			return c, nil
+		}
+	}
+	c := Condition{Var: s}
+	if strings.HasPrefix(s, "!") {
+		c = Condition{Var: strings.TrimSpace(s[1:]), Not: true}
+	}
+	if c.Var == "" || strings.ContainsAny(c.Var, " \t") {
+		return Condition{}, errors.New("expected a variable or a comparison, got " + strconv.Quote(s))
+	}
+	return c, nil
+}
+
+// parseLiteral reads the value a condition compares with. Quotes are optional around strings.
+func parseLiteral(s string) interface{} {
+	if s == "true" || s == "false" {
+		return s == "true"
+	}
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	return s
+}
+
+// Holds returns true if the condition is true for the variables
+func (c Condition) Holds(vars Variables) bool {
+	if c.Var == "" {
+		return true
+	}
+	v := vars[c.Var]
+	switch c.Op {
+	case "":
+		return IsTruthy(v) != c.Not
+	case "==":
+		return v == c.Value || (v == nil && !IsTruthy(c.Value))
+	case "!=":
+		return v != c.Value && !(v == nil && !IsTruthy(c.Value))
+	}
+	// Ordered comparisons are between ints, and a missing variable is 0
+	i, ok := v.(int)
+	if !ok && v != nil {
+		return false
+	}
+	n := c.Value.(int)
+	switch c.Op {
+	case "<":
+		return i < n
+	case "<=":
+		return i <= n
+	case ">":
+		return i > n
+	}
+	return i >= n
+}
+
+// IsTruthy returns true for true, a non-zero int or a non-empty string
+func IsTruthy(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"ebiten-demo/game"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// DefaultGamepadDeadzone is used when the bindings file does not set a deadzone
+const DefaultGamepadDeadzone = 0.25
+
+// GamepadButtonNames are the names of standard layout buttons used in bindings files
+var GamepadButtonNames = map[string]ebiten.StandardGamepadButton{
+	"RightBottom":      ebiten.StandardGamepadButtonRightBottom,
+	"RightRight":       ebiten.StandardGamepadButtonRightRight,
+	"RightLeft":        ebiten.StandardGamepadButtonRightLeft,
+	"RightTop":         ebiten.StandardGamepadButtonRightTop,
+	"FrontTopLeft":     ebiten.StandardGamepadButtonFrontTopLeft,
+	"FrontTopRight":    ebiten.StandardGamepadButtonFrontTopRight,
+	"FrontBottomLeft":  ebiten.StandardGamepadButtonFrontBottomLeft,
+	"FrontBottomRight": ebiten.StandardGamepadButtonFrontBottomRight,
+	"CenterLeft":       ebiten.StandardGamepadButtonCenterLeft,
+	"CenterRight":      ebiten.StandardGamepadButtonCenterRight,
+	"CenterCenter":     ebiten.StandardGamepadButtonCenterCenter,
+	"LeftStick":        ebiten.StandardGamepadButtonLeftStick,
+	"RightStick":       ebiten.StandardGamepadButtonRightStick,
+	"LeftTop":          ebiten.StandardGamepadButtonLeftTop,
+	"LeftBottom":       ebiten.StandardGamepadButtonLeftBottom,
+	"LeftLeft":         ebiten.StandardGamepadButtonLeftLeft,
+	"LeftRight":        ebiten.StandardGamepadButtonLeftRight,
+}
+
+// GamepadStickNames are the names of stick directions used in bindings files
+var GamepadStickNames = map[string]GamepadBinding{
+	"LeftStickUp":     {Axis: ebiten.StandardGamepadAxisLeftStickVertical, Sign: -1},
+	"LeftStickDown":   {Axis: ebiten.StandardGamepadAxisLeftStickVertical, Sign: 1},
+	"LeftStickLeft":   {Axis: ebiten.StandardGamepadAxisLeftStickHorizontal, Sign: -1},
+	"LeftStickRight":  {Axis: ebiten.StandardGamepadAxisLeftStickHorizontal, Sign: 1},
+	"RightStickUp":    {Axis: ebiten.StandardGamepadAxisRightStickVertical, Sign: -1},
+	"RightStickDown":  {Axis: ebiten.StandardGamepadAxisRightStickVertical, Sign: 1},
+	"RightStickLeft":  {Axis: ebiten.StandardGamepadAxisRightStickHorizontal, Sign: -1},
+	"RightStickRight": {Axis: ebiten.StandardGamepadAxisRightStickHorizontal, Sign: 1},
+}
+
+// GamepadBinding is a standard layout button, or a stick pushed in one direction if Sign is not 0
+type GamepadBinding struct {
+	Button ebiten.StandardGamepadButton
+	Axis   ebiten.StandardGamepadAxis
+	Sign   int // -1 for up or left along the axis, 1 for down or right along the axis, or 0 for a button
+}
+
+// IsPressed returns true if the button is held down, or if the stick is pushed past the deadzone mostly in the direction of the binding.
+// Diagonals count as both of their directions.
+func (b GamepadBinding) IsPressed(id ebiten.GamepadID, deadzone float64) bool {
+	if b.Sign == 0 {
+		return ebiten.IsStandardGamepadButtonPressed(id, b.Button)
+	}
+	// The horizontal and vertical axes of a stick are numbered next to each other, so flipping the low bit gives the other axis of the same stick
+	other := b.Axis ^ 1
+	v := ebiten.StandardGamepadAxisValue(id, b.Axis) * float64(b.Sign)
+	magnitude := math.Hypot(v, ebiten.StandardGamepadAxisValue(id, other))
+	// Within 60 degrees of the direction, so diagonals hold down both directions
+	return magnitude > deadzone && v >= magnitude/2
+}
+
+// GamepadInput reads actions from a single standard layout gamepad
+type GamepadInput struct {
+	game.ActionState
+	ID       ebiten.GamepadID
+	Bindings map[game.Action][]GamepadBinding
+	Deadzone float64
+}
+
+func (p *GamepadInput) Update() {
+	for a := game.Action(0); a < game.ActionCount; a++ {
+		pressed := false
+		for _, b := range p.Bindings[a] {
+			if b.IsPressed(p.ID, p.Deadzone) {
+				pressed = true
+				break
+			}
+		}
+		p.Set(a, pressed)
+	}
+}
+
+// Gamepads tracks every connected standard layout gamepad as they are plugged in and out,
+// and acts as a single input that holds an action down while any gamepad does.
+type Gamepads struct {
+	game.ActionState
+	Bindings map[game.Action][]GamepadBinding
+	Deadzone float64
+	Pads     map[ebiten.GamepadID]*GamepadInput // The connected gamepads by id
+}
+
+func (g *Gamepads) Update() {
+	connected := map[ebiten.GamepadID]bool{}
+	for _, id := range ebiten.AppendGamepadIDs(nil) {
+		connected[id] = true
+		// Gamepads without a standard layout mapping can't be bound by button name
+		if _, ok := g.Pads[id]; !ok && ebiten.IsStandardGamepadLayoutAvailable(id) {
+			g.Pads[id] = &GamepadInput{
+				ID:       id,
+				Bindings: g.Bindings,
+				Deadzone: g.Deadzone,
+			}
+		}
+	}
+	for id := range g.Pads {
+		if !connected[id] {
+			delete(g.Pads, id)
+		}
+	}
+
+	for _, p := range g.Pads {
+		p.Update()
+	}
+	for a := game.Action(0); a < game.ActionCount; a++ {
+		pressed := false
+		for _, p := range g.Pads {
+			if p.IsPressed(a) {
+				pressed = true
+				break
+			}
+		}
+		g.Set(a, pressed)
+	}
+}
+
+// IDs returns the ids of the connected gamepads in ascending order, which is the order they were connected in.
+// This is used to assign gamepads to players.
+func (g *Gamepads) IDs() []ebiten.GamepadID {
+	var ids []ebiten.GamepadID
+	for id := range g.Pads {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// LoadGamepads reads the gamepad bindings from a bindings json file
+func LoadGamepads(path string) (*Gamepads, error) {
+	jsonBindings, err := game.ReadBindings(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gamepads := &Gamepads{
+		Bindings: map[game.Action][]GamepadBinding{},
+		Deadzone: jsonBindings.GamepadDeadzone,
+		Pads:     map[ebiten.GamepadID]*GamepadInput{},
+	}
+	if gamepads.Deadzone == 0 {
+		gamepads.Deadzone = DefaultGamepadDeadzone
+	}
+	if gamepads.Deadzone < 0 || gamepads.Deadzone >= 1 {
+		return nil, fmt.Errorf("%s: gamepadDeadzone: must be from 0 to 1, got %v", path, gamepads.Deadzone)
+	}
+
+	for name, buttons := range jsonBindings.Gamepad {
+		a, ok := game.ActionNames[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: gamepad.%s: unknown action", path, name)
+		}
+		for i, buttonName := range buttons {
+			b, ok := GamepadStickNames[buttonName]
+			if !ok {
+				button, ok := GamepadButtonNames[buttonName]
+				if !ok {
+					return nil, fmt.Errorf("%s: gamepad.%s[%d]: unknown button %q", path, name, i, buttonName)
+				}
+				b = GamepadBinding{Button: button}
+			}
+			gamepads.Bindings[a] = append(gamepads.Bindings[a], b)
+		}
+	}
+	return gamepads, nil
+}
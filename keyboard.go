@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"ebiten-demo/game"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// KeyboardInput reads actions from the keyboard. An action is held down while any of its bound keys are.
+type KeyboardInput struct {
+	game.ActionState
+	Bindings map[game.Action][]ebiten.Key
+}
+
+func (k *KeyboardInput) Update() {
+	for a := game.Action(0); a < game.ActionCount; a++ {
+		pressed := false
+		for _, key := range k.Bindings[a] {
+			if ebiten.IsKeyPressed(key) {
+				pressed = true
+				break
+			}
+		}
+		k.Set(a, pressed)
+	}
+}
+
+// LoadKeyboardInput reads the keyboard bindings from a bindings json file
+func LoadKeyboardInput(path string) (*KeyboardInput, error) {
+	jsonBindings, err := game.ReadBindings(path)
+	if err != nil {
+		return nil, err
+	}
+
+	keyboard := &KeyboardInput{Bindings: map[game.Action][]ebiten.Key{}}
+	for name, keys := range jsonBindings.Keyboard {
+		a, ok := game.ActionNames[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: keyboard.%s: unknown action", path, name)
+		}
+		for i, keyName := range keys {
+			key, ok := ParseKey(keyName)
+			if !ok {
+				return nil, fmt.Errorf("%s: keyboard.%s[%d]: unknown key %q", path, name, i, keyName)
+			}
+			keyboard.Bindings[a] = append(keyboard.Bindings[a], key)
+		}
+	}
+	return keyboard, nil
+}
+
+// ParseKey looks up a key by the name ebiten gives it, ignoring case
+func ParseKey(name string) (ebiten.Key, bool) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if strings.EqualFold(k.String(), name) {
+			return k, true
+		}
+	}
+	return 0, false
+}
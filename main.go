@@ -1,7 +1,8 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
+	"fmt"
 	"image"
 	"image/color"
 	_ "image/png"
@@ -9,265 +10,72 @@ import (
 	"os"
 	"sort"
 	"strings"
+	"time"
+
+	"ebiten-demo/game"
 
 	"golang.org/x/image/font"
-	"golang.org/x/image/font/gofont/goregular"
-	"golang.org/x/image/font/opentype"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
 )
 
-// Game is an ebiten Game interface implemetation plus custom struct data
-type Game struct {
-	Player              Player
-	Characters          []Character
-	Enemies             []Enemy
-	Weapons             []Weapon
-	Projectiles         []Projectile
-	Doodads             []Doodad
-	Tiles               []Tile
-	RenderTargets       []*RenderTarget
-	Sprites             map[string]Sprite
-	Font                font.Face
-	Options             *ebiten.DrawImageOptions
-	InteractionTarget   InteractionTarget // The target of another game element that the player is having a dialogue interaction with, or nil.
-	EnemyCollision      *Enemy
-	ProjectileCollision *Projectile
-}
-
-type InteractionTarget interface {
-	Dialogue() string    // The current dialogue to render
-	Options() [][]string // The options for the current dialogue, or empty
-	SelectOption(int)    // Selects a next or previous option
-	SelectedOption() int // Returns the selected option
-	AdvanceRune()        // Advances to the next rune
-	AdvancePhrase()      // Advances to the next phrase
-	IsExhausted() bool   // Returns true if the current dialogue tree is complete
-}
-
-// Player represents the player character
-type Player struct {
-	X         int        // The current X screen offset of the player
-	Y         int        // The current Y screen offset of the player
-	Animation bool       // Whether or not the player is in a special animation or the normal stand/walk cycle.
-	LastDir   ebiten.Key // The last direction the player faced (never -1)
-	Sprite    Sprite     // The current sprite for the player
-	FrameNum  int        // The current frame of the sprite for the player
-	FrameDur  int        // The duration of the current frame of the sprite for the player
-	Health    int        // How much health the player has
-	Weapon    *Weapon    // The weapon the player has equipped
-}
-
-type DialogueGraph struct {
-	Nodes   map[string]*DialogueNode
-	Edges   map[string][]string
-	NodeKey string // The current node of dialogue the player is on
-	RootKey string // The root node of the current dialogue tree
-}
-
-type DialogueNode struct {
-	Phrase    string     // The phrase of dialogue
-	Options   [][]string // The options on the node, or empty
-	RuneNum   int
-	OptionNum int  // Which option is selected
-	End       bool // Whether or not to end the interaction after this node is completed.
-}
-
-// Character represents an npc character
-type Character struct {
-	X              int                       // The current X screen offset of the character
-	Y              int                       // The current Y screen offset of the character
-	Animation      bool                      // Whether or not the character is in a special animation or the normal stand/walk cycle.
-	LastDir        ebiten.Key                // The last direction the character faced (never -1)
-	Sprite         Sprite                    // The current sprite for the character
-	FrameNum       int                       // The current frame of the sprite for the character
-	DialogueGraphs map[string]*DialogueGraph // The dialogue graphs the character has
-	DialogueKey    string                    // The current dialogue graph the character has loaded
-}
-
-func (c *Character) Dialogue() string {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	node := graph.Nodes[graph.NodeKey]
-	return node.Phrase[:node.RuneNum]
-}
-
-func (c *Character) Options() [][]string {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	node := graph.Nodes[graph.NodeKey]
-	return node.Options
+// Window is an ebiten Game interface implementation that runs a game and draws it
+type Window struct {
+	*game.Game
+	Updater  Updater   // Steps the game every frame. This is the game itself, or a recorder or replayer wrapping it.
+	Gamepads *Gamepads // The connected gamepads, which are also part of the game input
+	Font     font.Face
+	Options  *ebiten.DrawImageOptions
 }
 
-func (c *Character) SelectOption(dir int) {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	node := graph.Nodes[graph.NodeKey]
-	node.OptionNum = Min(Max(node.OptionNum+dir, 0), len(node.Options)-1)
+// Updater steps a game by one frame
+type Updater interface {
+	Update() error
 }
 
-func (c *Character) SelectedOption() int {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	node := graph.Nodes[graph.NodeKey]
-	return node.OptionNum
-}
-
-func (c *Character) AdvanceRune() {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	node := graph.Nodes[graph.NodeKey]
-	node.RuneNum = Min(node.RuneNum+1, len(node.Phrase))
-}
-
-func (c *Character) AdvancePhrase() {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	connections := graph.Edges[graph.NodeKey]
-	node := graph.Nodes[graph.NodeKey]
-	// If the node has no options then there is only a single node to advance to
-	if len(node.Options) == 0 && len(connections) > 0 {
-		graph.Nodes[graph.NodeKey].RuneNum = 0
-		graph.Nodes[graph.NodeKey].OptionNum = 0
-		graph.NodeKey = connections[0]
-	} else if len(node.Options) > 0 {
-		options := node.Options[node.OptionNum]
-		if Contains(connections, options[1]) {
-			graph.Nodes[graph.NodeKey].RuneNum = 0
-			graph.Nodes[graph.NodeKey].OptionNum = 0
-			graph.NodeKey = options[1]
-		}
+func (g *Window) Update() error {
+	if err := g.Updater.Update(); err != nil {
+		return err
 	}
-}
-
-func (c *Character) IsExhausted() bool {
-	graph := c.DialogueGraphs[c.DialogueKey]
-	return graph.Nodes[graph.NodeKey].End
-}
-
-// Enemy represents an enemy
-type Enemy struct {
-	X         int        // The current X screen offset of the enemy
-	Y         int        // The current Y screen offset of the enemy
-	Animation bool       // Whether or not the enemy is in a special animation or the normal stand/walk cycle.
-	LastDir   ebiten.Key // The last direction the enemy faced (never -1)
-	Sprite    Sprite     // The current sprite for the enemy
-	FrameNum  int        // The current frame of the sprite for the enemy
-	Behavior  Behavior   // The active behavior of the enemy
-}
-
-// Weapon represents a weapon held by something
-type Weapon struct {
-	Sprite      Sprite       // The current sprite for the weapon
-	FrameNum    int          // The current frame of the sprite for the weapon
-	Wielder     RenderTarget // The wielder of the weapon. The weapon is drawn relative to the wielder.
-	IsAttacking bool         // Whether or not the weapon is attacking and should be drawn.
-}
-
-// Projectile represents a projectile
-type Projectile struct {
-	X        int        // The current X screen offset of the projectile
-	Y        int        // The current Y screen offset of the projectile
-	Sprite   Sprite     // The current sprite for the projectile
-	FrameNum int        // The current frame of the sprite for the projectile
-	Speed    int        // The number of pixels the projectile moves per frame
-	Dir      ebiten.Key // The direction the projection is travelling
-	IsEnemy  bool       // Whether or not the projecile is enemy or friendly
-}
-
-// Doodad represents a static environmental item
-type Doodad struct {
-	X        int    // The current X screen offset of the doodad
-	Y        int    // The current Y screen offset of the doodad
-	Sprite   Sprite // The current sprite for the doodad
-	FrameNum int    // The current frame of the sprite for the doodad
-}
-
-// Tile represents a floor texture
-type Tile struct {
-	X        int    // The current X screen offset of the doodad
-	Y        int    // The current Y screen offset of the doodad
-	Sprite   Sprite // The current sprite for the doodad
-	FrameNum int    // The current frame of the sprite for the doodad
-	Collider bool   // Whether or not the tile can be collided with
-}
-
-// Sprite represents an image with a number of sub-frames in it to be rendered via rectangles
-type Sprite struct {
-	FrameWidth  int
-	FrameHeight int
-	FrameLen    int           // How many frames are in the sprite
-	FrameDur    int           // How many render frames to display a single frame of the sprite
-	Handles     []image.Point // An array of coordinates inside the sprite for attaching other sprites to
-	Image       *ebiten.Image
-}
-
-// SpriteJSON represents the json to be read from the sprite json file.
-type SpriteJSON struct {
-	FrameDur    int           `json:"frameDuration"`
-	FrameLen    int           `json:"frameLen"`
-	FrameHeight int           `json:"frameHeight"`
-	FrameWidth  int           `json:"frameWidth"`
-	Handles     []image.Point `json:"handles"`
-	Image       string        `json:"image"`
-}
-
-// DialogueJSON represents the json to be read from the dialogue json file.
-type DialogueJSON struct {
-	ID          string     `json:"id"`
-	Phrase      string     `json:"phrase"`
-	Options     [][]string `json:"options"`
-	Connections []string   `json:"connections"`
-	End         bool       `json:"end"`
-}
-
-// IsOtherDirectionJustReleased checks if one of the three cardinal directions other than the key passed in was just released
-// This is used to reset the walk cycle animation for a new direction
-func IsOtherDirectionJustReleased(key ebiten.Key) bool {
-	switch key {
-	case ebiten.KeyLeft:
-		return inpututil.IsKeyJustReleased(ebiten.KeyRight) || inpututil.IsKeyJustReleased(ebiten.KeyUp) || inpututil.IsKeyJustReleased(ebiten.KeyDown)
-	case ebiten.KeyRight:
-		return inpututil.IsKeyJustReleased(ebiten.KeyLeft) || inpututil.IsKeyJustReleased(ebiten.KeyUp) || inpututil.IsKeyJustReleased(ebiten.KeyDown)
-	case ebiten.KeyUp:
-		return inpututil.IsKeyJustReleased(ebiten.KeyLeft) || inpututil.IsKeyJustReleased(ebiten.KeyRight) || inpututil.IsKeyJustReleased(ebiten.KeyDown)
-	case ebiten.KeyDown:
-		return inpututil.IsKeyJustReleased(ebiten.KeyLeft) || inpututil.IsKeyJustReleased(ebiten.KeyRight) || inpututil.IsKeyJustReleased(ebiten.KeyUp)
-	default:
-		return false
+	if g.Settings.Fullscreen != ebiten.IsFullscreen() {
+		ebiten.SetFullscreen(g.Settings.Fullscreen)
 	}
+	return nil
 }
 
-// IsLeastKeyPressDuration checks if a direction key is the most recently pressed
-// This is used to set the correct walk direction if the player is holding down multiple walk buttons at once and releases one of them
-func IsLeastKeyPressDuration(key ebiten.Key) bool {
-	d := inpututil.KeyPressDuration(key)
-	up := inpututil.KeyPressDuration(ebiten.KeyUp)
-	down := inpututil.KeyPressDuration(ebiten.KeyDown)
-	left := inpututil.KeyPressDuration(ebiten.KeyLeft)
-	right := inpututil.KeyPressDuration(ebiten.KeyRight)
-	return d > 0 &&
-		(key == ebiten.KeyUp || d < up || up == 0) &&
-		(key == ebiten.KeyDown || d < down || down == 0) &&
-		(key == ebiten.KeyLeft || d < left || left == 0) &&
-		(key == ebiten.KeyRight || d < right || right == 0)
-}
-
-func (g *Game) Update() error {
-
-	UpdateInteraction(g)
-	if g.InteractionTarget != nil {
-		return nil
+// Draw draws each scene that is shown, from the bottom up
+func (g *Window) Draw(screen *ebiten.Image) {
+	for _, scene := range g.DrawnScenes() {
+		switch s := scene.(type) {
+		case *game.PlayScene:
+			g.DrawWorld(screen)
+		case *game.TitleScene:
+			screen.Fill(color.Black)
+			g.DrawMenu(screen, s.Menu)
+		case *game.PauseScene:
+			g.DrawDim(screen)
+			// The settings menu replaces the pause menu while it is open
+			if s == g.Scene() {
+				g.DrawMenu(screen, s.Menu)
+			}
+		case *game.SettingsScene:
+			g.DrawMenu(screen, s.Menu)
+		case *game.InventoryScene:
+			g.DrawDim(screen)
+			g.DrawMenu(screen, s.Menu)
+		case *game.GameOverScene:
+			screen.Fill(color.Black)
+			g.DrawMenu(screen, s.Menu)
+		}
 	}
-
-	UpdatePlayer(g)
-	UpdateCharacters(g)
-	UpdateEnemies(g)
-	UpdateProjectiles(g)
-	UpdateDamage(g)
-
-	return nil
 }
 
-func (g *Game) Draw(screen *ebiten.Image) {
-	render := []RenderTarget{&g.Player}
+// DrawWorld draws the game world, the HUD and the dialogue box
+func (g *Window) DrawWorld(screen *ebiten.Image) {
+	render := []game.RenderTarget{&g.Player}
 	if g.Player.Weapon.IsAttacking {
 		render = append(render, g.Player.Weapon)
 	}
@@ -292,13 +100,31 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		render = append(render, &g.Projectiles[i])
 	}
 
+	for i := range g.Pickups {
+		render = append(render, &g.Pickups[i])
+	}
+
 	sort.Slice(render, func(i, j int) bool { return render[i].RenderOrder() < render[j].RenderOrder() })
 
+	// Render targets are positioned in world space, so the camera translates all of them into screen space
 	for _, t := range render {
-		screen.DrawImage(t.RenderImage(), t.RenderOptions())
+		if !t.RenderVisible() {
+			continue
+		}
+		o := &ebiten.DrawImageOptions{}
+		p := t.RenderPosition().Sub(image.Pt(g.Camera.X, g.Camera.Y))
+		if w, ok := t.(*game.Weapon); ok {
+			// Weapons are turned around their handle to face the swing, so they cover the same rect as their hitbox
+			WeaponGeoM(&o.GeoM, w)
+			p = w.Hand().Sub(image.Pt(g.Camera.X, g.Camera.Y))
+		}
+		o.GeoM.Translate(float64(p.X), float64(p.Y))
+		screen.DrawImage(EbitenImage(t.RenderImage()), o)
 	}
 
-	// If in a text interaction, draw the text box last over eveything else.
+	g.DrawHUD(screen)
+
+	// If in a text interaction, draw the text box last over eveything else. The text box is drawn in screen space.
 	if g.InteractionTarget != nil {
 		leftWidth := g.Sprites["dialogueFrameLeft"].Image.Bounds().Dx()
 		rightWidth := g.Sprites["dialogueFrameRight"].Image.Bounds().Dx()
@@ -306,34 +132,24 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.Options.GeoM.Reset()
 		g.Options.GeoM.Scale(39, 1)
 		g.Options.GeoM.Translate(float64(leftWidth), 0)
-		screen.DrawImage(g.Sprites["dialogueFrameCenter"].Image, g.Options)
+		screen.DrawImage(EbitenImage(g.Sprites["dialogueFrameCenter"].Image), g.Options)
 
 		g.Options.GeoM.Reset()
-		screen.DrawImage(g.Sprites["dialogueFrameLeft"].Image, g.Options)
+		screen.DrawImage(EbitenImage(g.Sprites["dialogueFrameLeft"].Image), g.Options)
 
-		g.Options.GeoM.Translate(float64(320-rightWidth), 0)
-		screen.DrawImage(g.Sprites["dialogueFrameRight"].Image, g.Options)
+		g.Options.GeoM.Translate(float64(game.ScreenWidth-rightWidth), 0)
+		screen.DrawImage(EbitenImage(g.Sprites["dialogueFrameRight"].Image), g.Options)
 
-		dialogue := strings.Split(g.InteractionTarget.Dialogue(), " ")
+		// Wrap the phrase to the inside of the text box, which breaks lines between words or between the characters of
+		// languages written without spaces
+		measure := func(s string) int {
+			bound, _ := font.BoundString(g.Font, s)
+			return (bound.Max.X - bound.Min.X).Ceil()
+		}
 		line := 1
-		for i := 0; i < len(dialogue); line++ {
-			var render []string
-			// Loop over words until they surpass the screen length, then back up by one word
-			for w := 0; w < 320-leftWidth-rightWidth && i < len(dialogue); i++ {
-				render = append(render, dialogue[i])
-				join := strings.Join(render, " ")
-				// Calculate the rect size of the string
-				bound, _ := font.BoundString(g.Font, join)
-				w = (bound.Max.X - bound.Min.X).Ceil()
-				// If the rect overflows the screen, go back by one word
-				if w >= 320-leftWidth-rightWidth {
-					i--
-				}
-			}
-			if i < len(dialogue) {
-				render = render[:len(render)-1]
-			}
-			text.Draw(screen, strings.Join(render, " "), g.Font, 8, line*18, color.White)
+		for _, l := range game.WrapText(g.InteractionTarget.Dialogue(), game.ScreenWidth-leftWidth-rightWidth, measure) {
+			text.Draw(screen, l, g.Font, 8, line*18, color.White)
+			line++
 		}
 		options := g.InteractionTarget.Options()
 		if len(options) > 0 {
@@ -344,193 +160,214 @@ func (g *Game) Draw(screen *ebiten.Image) {
 			text.Draw(screen, strings.Join(o, " "), g.Font, 8, line*18, color.White)
 			g.Options.GeoM.Reset()
 			g.Options.GeoM.Translate(float64(8+18*g.InteractionTarget.SelectedOption()), float64(line*12))
-			screen.DrawImage(g.Sprites["selectBox"].Image, g.Options)
+			screen.DrawImage(EbitenImage(g.Sprites["selectBox"].Image), g.Options)
 		}
 	}
 }
 
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return 320, 240
+// WeaponGeoM sets m to move the handle of a weapon image to the origin and turn it to face the direction of the swing
+func WeaponGeoM(m *ebiten.GeoM, w *game.Weapon) {
+	handle := w.RenderHandle()
+	m.Translate(float64(-handle.X), float64(-handle.Y))
+	turn := w.Turn()
+	var t ebiten.GeoM
+	t.SetElement(0, 0, float64(turn.A))
+	t.SetElement(0, 1, float64(turn.B))
+	t.SetElement(1, 0, float64(turn.C))
+	t.SetElement(1, 1, float64(turn.D))
+	m.Concat(t)
 }
 
-func main() {
-	ebiten.SetWindowSize(640, 480)
-	ebiten.SetWindowTitle("grame")
+// DrawMenu draws a menu in the middle of the screen in the current locale, with its options one under the other and the selected
+// one in a select box
+func (g *Window) DrawMenu(screen *ebiten.Image, m *game.Menu) {
+	const rowHeight = 24
+	title, options := g.MenuText(m)
+	top := (game.ScreenHeight - rowHeight*(len(options)+1)) / 2
+	g.DrawCentered(screen, title, top+rowHeight/2)
+
+	box := g.Sprites["selectBox"].Image.Bounds()
+	for i, option := range options {
+		y := top + rowHeight*(i+1)
+		x := g.DrawCentered(screen, option, y+rowHeight/2)
+		if i == m.Selected {
+			// Stretch the select box around the option
+			bound, _ := font.BoundString(g.Font, option)
+			width := (bound.Max.X - bound.Min.X).Ceil() + 12
+			g.Options.GeoM.Reset()
+			g.Options.GeoM.Scale(float64(width)/float64(box.Dx()), 1)
+			g.Options.GeoM.Translate(float64(x-6), float64(y))
+			screen.DrawImage(EbitenImage(g.Sprites["selectBox"].Image), g.Options)
+		}
+	}
+}
 
-	sprites, err := os.ReadFile("./sprites/sprites.json")
-	if err != nil {
-		log.Fatal(err)
+// DrawDim darkens everything drawn so far, so an overlay scene stands out from the suspended scenes under it
+func (g *Window) DrawDim(screen *ebiten.Image) {
+	ebitenutil.DrawRect(screen, 0, 0, game.ScreenWidth, game.ScreenHeight, color.RGBA{A: 0xa0})
+}
+
+// DrawHUD draws the player status over the world. The HUD is laid out in screen space.
+func (g *Window) DrawHUD(screen *ebiten.Image) {
+	for _, e := range game.HUD(g.Game) {
+		if e.Image == nil {
+			bound, _ := font.BoundString(g.Font, e.Text)
+			text.Draw(screen, e.Text, g.Font, e.Position.X, e.Position.Y-bound.Min.Y.Ceil()/2, color.White)
+			continue
+		}
+		g.Options.GeoM.Reset()
+		g.Options.GeoM.Translate(float64(e.Position.X), float64(e.Position.Y))
+		screen.DrawImage(EbitenImage(e.Image), g.Options)
 	}
+}
+
+// DrawCentered draws a line of text centered horizontally on the screen and vertically on y, and returns the x it starts at
+func (g *Window) DrawCentered(screen *ebiten.Image, s string, y int) int {
+	bound, _ := font.BoundString(g.Font, s)
+	x := (game.ScreenWidth - (bound.Max.X - bound.Min.X).Ceil()) / 2
+	// Text is drawn from its baseline, so move it down by half the height of the text above the baseline
+	text.Draw(screen, s, g.Font, x, y-bound.Min.Y.Ceil()/2, color.White)
+	return x
+}
 
-	var jsonSprites []SpriteJSON
-	err = json.Unmarshal(sprites, &jsonSprites)
+func (g *Window) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	return game.ScreenWidth, game.ScreenHeight
+}
+
+// EbitenImage returns a game image as the ebiten image it was loaded as
+func EbitenImage(img game.Image) *ebiten.Image {
+	return img.(*ebiten.Image)
+}
+
+// LoadEbitenImage loads an image file into an ebiten image for game.LoadImage
+func LoadEbitenImage(path string) (game.Image, error) {
+	img, _, err := ebitenutil.NewImageFromFile(path)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
+	return img, nil
+}
 
-	linkSprites := map[string]Sprite{}
-	for _, v := range jsonSprites {
-		sprite, _, err := ebitenutil.NewImageFromFile("./sprites/" + v.Image)
+// Validate checks dialogue files and prints every problem found in them. It returns 1 if there were any problems.
+func Validate(paths []string) int {
+	if len(paths) == 0 {
+		paths = []string{"./dialogue/dialogue.json"}
+	}
+	code := 0
+	for _, path := range paths {
+		problems, err := game.ValidateDialogue(path)
 		if err != nil {
-			log.Fatal(err)
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
 		}
-
-		// camelCase the filenames without the extension to make sprite keys
-		k := CamelCase(v.Image[:len(v.Image)-4])
-
-		linkSprites[k] = Sprite{
-			FrameDur:    v.FrameDur,
-			FrameLen:    v.FrameLen,
-			FrameHeight: v.FrameHeight,
-			FrameWidth:  v.FrameWidth,
-			Handles:     v.Handles,
-			Image:       sprite,
+		for _, p := range problems {
+			fmt.Fprintln(os.Stderr, p)
+			code = 1
 		}
 	}
+	return code
+}
 
-	sprite := linkSprites["linkStandSouth"]
+func main() {
+	// The validate subcommand checks the dialogue without starting the game
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(Validate(os.Args[2:]))
+	}
 
-	dialogues, err := os.ReadFile("./dialogue/dialogue.json")
-	if err != nil {
-		log.Fatal(err)
+	levelPath := flag.String("level", "./levels/field.json", "the level file to start in")
+	recordPath := flag.String("record", "", "record every frame of input to this file")
+	replayPath := flag.String("replay", "", "replay a recording made with -record")
+	verify := flag.Bool("verify", false, "with -replay, check the recording without opening a window and report the first divergence")
+	seed := flag.Int64("seed", 0, "the seed of random rolls like loot drops, or 0 to pick one from the time")
+	locale := flag.String("locale", game.DefaultLocale, "the language of dialogue and menus, like fr")
+	flag.Parse()
+
+	ebiten.SetWindowSize(640, 480)
+	ebiten.SetWindowTitle("grame")
+
+	game.LoadImage = LoadEbitenImage
+	// Verifying a replay has no sound device, so it keeps the silent sounds
+	if !*verify {
+		game.LoadSound = EbitenSoundLoader(audio.NewContext(game.SampleRate))
 	}
 
-	var jsonDialogues map[string][]DialogueJSON
-	err = json.Unmarshal(dialogues, &jsonDialogues)
+	face, err := game.LoadFonts("./fonts/fonts.json")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	dialogueGraphs := map[string]*DialogueGraph{}
-	for k, v := range jsonDialogues {
-		graph := DialogueGraph{
-			Nodes: map[string]*DialogueNode{},
-			Edges: map[string][]string{},
-		}
-		for i := 0; i < len(v); i++ {
-			node := DialogueNode{
-				Phrase:    v[i].Phrase,
-				Options:   v[i].Options,
-				RuneNum:   0,
-				OptionNum: 0,
-				End:       v[i].End,
-			}
-			graph.Nodes[v[i].ID] = &node
-			graph.Edges[v[i].ID] = v[i].Connections
-			if i == 0 {
-				graph.RootKey = v[i].ID
-				graph.NodeKey = v[i].ID
-			}
-		}
+	op := &ebiten.DrawImageOptions{}
 
-		dialogueGraphs[k] = &graph
+	keyboard, err := LoadKeyboardInput("./input/bindings.json")
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	f, err := opentype.Parse(goregular.TTF)
+	gamepads, err := LoadGamepads("./input/bindings.json")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	face, err := opentype.NewFace(f, &opentype.FaceOptions{
-		Size:    12,
-		DPI:     72,
-		Hinting: font.HintingFull,
-	})
+	g, err := game.LoadGame(".", *levelPath, &game.CombinedInput{Inputs: []game.Input{keyboard, gamepads}})
 	if err != nil {
 		log.Fatal(err)
 	}
+	if err := g.SetLocale(*locale); err != nil {
+		log.Fatal(err)
+	}
+	g.Seed = *seed
+	if g.Seed == 0 {
+		g.Seed = time.Now().UnixNano()
+	}
 
-	op := &ebiten.DrawImageOptions{}
-
-	var tiles []Tile
+	// Replays start from where the recording did, so only a new game starts at the title screen
+	if *replayPath == "" {
+		g.PushScene(game.NewTitleScene())
+	}
 
-	for x := 8; x < 320; x += 16 {
-		for y := 16; y < 320; y += 16 {
-			tiles = append(tiles, Tile{
-				X:        x,
-				Y:        y,
-				FrameNum: 0,
-				Sprite:   linkSprites["grass"],
-			})
+	window := &Window{
+		Game:     g,
+		Updater:  g,
+		Gamepads: gamepads,
+		Font:     face,
+		Options:  op,
+	}
+	var recorder *game.Recorder
+	var replayer *game.Replayer
+	if *replayPath != "" {
+		recording, err := game.ReadRecording(*replayPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if replayer, err = game.NewReplayer(g, recording); err != nil {
+			log.Fatal(err)
 		}
+		if *verify {
+			if err := replayer.Verify(); err != nil {
+				log.Fatal(err)
+			}
+			log.Printf("replay matched all %d recorded frames", len(recording.Frames))
+			return
+		}
+		window.Updater = replayer
+	} else if *recordPath != "" {
+		if recorder, err = game.NewRecorder(g); err != nil {
+			log.Fatal(err)
+		}
+		window.Updater = recorder
 	}
 
-	tiles = append(tiles, Tile{
-		X:        64,
-		Y:        128,
-		FrameNum: 0,
-		Sprite:   linkSprites["stump"],
-		Collider: true,
-	})
-
-	tiles = append(tiles, Tile{
-		X:        96,
-		Y:        160,
-		FrameNum: 0,
-		Sprite:   linkSprites["stump"],
-		Collider: true,
-	})
-
-	weapons := []Weapon{
-		{
-			Sprite: linkSprites["swordEast"],
-		},
+	err = ebiten.RunGame(window)
+	if recorder != nil {
+		if err := recorder.WriteFile(*recordPath); err != nil {
+			log.Println(err)
+		}
 	}
-
-	game := &Game{
-		Player: Player{
-			X:         8,
-			Y:         21,
-			LastDir:   ebiten.KeyDown,
-			Animation: false,
-			FrameNum:  0,
-			Sprite:    sprite,
-			Health:    100,
-			Weapon:    &weapons[0],
-		},
-		Characters: []Character{
-			{
-				X:              32,
-				Y:              32,
-				FrameNum:       0,
-				Sprite:         linkSprites["elderStandSouth"],
-				DialogueGraphs: dialogueGraphs,
-				DialogueKey:    "elder",
-			},
-		},
-		Enemies: []Enemy{
-			{
-				X:        256,
-				Y:        128,
-				FrameNum: 0,
-				Sprite:   linkSprites["skeletonWizardStandSouth"],
-				Behavior: Behavior{
-					Pause: 60,
-				},
-			},
-		},
-		Doodads: []Doodad{
-			{
-				X:        128,
-				Y:        128,
-				FrameNum: 0,
-				Sprite:   linkSprites["tree"],
-			},
-			{
-				X:        256,
-				Y:        256,
-				FrameNum: 0,
-				Sprite:   linkSprites["tree"],
-			},
-		},
-		Tiles:   tiles,
-		Weapons: weapons,
-		Sprites: linkSprites,
-		Font:    face,
-		Options: op,
+	if replayer != nil && replayer.Divergence != nil {
+		log.Println(replayer.Divergence)
 	}
-
-	if err := ebiten.RunGame(game); err != nil {
+	if err != nil && err != game.ErrReplayEnded && err != game.ErrQuit {
 		panic(err)
 	}
 }